@@ -0,0 +1,128 @@
+// Package app збирає застосунок: сховища, менеджер токенів та HTTP-обробники створюються один раз
+// і передаються обробникам через їхні інтерфейси.
+package app
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/metrics"
+	"github.com/ChomuCake/uni-golang-labs/middleware"
+	"github.com/ChomuCake/uni-golang-labs/openapi"
+	"github.com/ChomuCake/uni-golang-labs/scheduler"
+	"github.com/ChomuCake/uni-golang-labs/tracing"
+	"github.com/ChomuCake/uni-golang-labs/util"
+)
+
+// FrontendDir - каталог статичних файлів фронтенду, що віддаються з кореня ("/", "/login.html" тощо)
+const FrontendDir = "./frontend"
+
+// App - зібраний застосунок
+type App struct {
+	Config   config.Config
+	Storage  *database.Storage
+	TokenMng util.TokenManager
+	Expenses *handlers.ExpenseHandler
+	Users    *handlers.UserHandler
+	Metrics  *metrics.Metrics
+	Logger   *slog.Logger
+
+	// Scheduler - фонові завдання; якщо задано, /readyz перевіряє, що він запущений
+	Scheduler *scheduler.Scheduler
+
+	handler http.Handler
+}
+
+// New створює застосунок поверх відкритого сховища storage; операції сховищ обмежуються
+// тайм-аутами cfg.Database.QueryTimeout та BatchTimeout, трасуються та записуються в метрики
+// і журнал slog.Default(). Налаштування обробників (напр. Expenses.IdempotencyTTL) можна змінити
+// до початку обслуговування запитів.
+func New(cfg config.Config, storage *database.Storage) *App {
+	m := metrics.New()
+	if storage.DB != nil {
+		m.RegisterDBStats(storage.DB, storage.Driver)
+	}
+
+	logger := slog.Default()
+	storage = storage.WithTimeout(cfg.Database.QueryTimeout, cfg.Database.BatchTimeout).
+		WithObserver(tracing.StoreObserver{Driver: storage.Driver}).
+		WithObserver(database.LogObserver{Logger: logger})
+	storage = m.InstrumentStorage(storage)
+	tokenMng := util.NewJWTTokenManager(cfg.Auth)
+
+	a := &App{
+		Config:   cfg,
+		Storage:  storage,
+		TokenMng: tokenMng,
+		Expenses: &handlers.ExpenseHandler{
+			ExpenseDB:      storage.Expenses,
+			HistoryDB:      storage.History,
+			UserDB:         storage.Users,
+			TokenMng:       tokenMng,
+			IdempotencyDB:  storage.Idempotency,
+			IdempotencyTTL: cfg.Retention.Idempotency,
+		},
+		Users: &handlers.UserHandler{
+			UserDB:   storage.Users,
+			TokenMng: tokenMng,
+			Logins:   m,
+		},
+		Metrics: m,
+		Logger:  logger,
+	}
+	a.handler = a.routes()
+
+	return a
+}
+
+func (a *App) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Кожен маршрут трасується та рахується в метриках HTTP і журналі доступу під своїм шаблоном шляху
+	// (метод записується окремо). Запит іншим методом на відомий шлях ServeMux відхиляє з 405 і заголовком Allow.
+	handle := func(pattern string, handler http.Handler) {
+		route := pattern
+		if _, path, ok := strings.Cut(pattern, " "); ok {
+			route = path
+		}
+		handler = a.Metrics.Instrument(route, handler)
+		handler = middleware.AccessLog(a.Logger, route, handler)
+		mux.Handle(pattern, tracing.Handler(route, handler))
+	}
+
+	// Фронтенд - плоский каталог файлів. Шаблони з одним сегментом шляху не перекривають API,
+	// тож на відомий шлях API з іншим методом ServeMux відповідає 405, а не сторінкою 404 фронтенду.
+	frontend := http.FileServer(http.Dir(FrontendDir))
+	handle("GET /{$}", frontend)
+	handle("GET /{file}", frontend)
+
+	handle("GET /healthz", http.HandlerFunc(a.healthz))
+	handle("GET /readyz", http.HandlerFunc(a.readyz))
+	handle("GET /version", http.HandlerFunc(a.version))
+	handle("GET /metrics", a.Metrics.Handler())
+	handle("GET "+openapi.SpecPath, openapi.Handler())
+	handle("GET "+openapi.UIPath, openapi.UIHandler())
+
+	// REST API: маршрути обробників монтуються під префіксом версії
+	for _, routes := range [][]handlers.Route{a.Users.Routes(), a.Expenses.Routes()} {
+		for _, route := range routes {
+			handle(route.Method()+" "+handlers.APIPrefix+route.Path(), route.Handler)
+		}
+	}
+
+	return middleware.RequestID(mux)
+}
+
+// Handler повертає кореневий HTTP-обробник застосунку (для http.Server або httptest.Server)
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// Close звільняє ресурси застосунку (підключення до бази даних)
+func (a *App) Close() error {
+	return a.Storage.Close()
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/middleware"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// newTestServer запускає застосунок зі сховищем у пам'яті на httptest.Server
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "0123456789abcdef0123456789abcdef"
+	cfg.Database.Driver = database.DriverMemory
+	cfg.Auth.TokenTTL = time.Minute
+
+	application := New(cfg, database.NewMemoryStorage())
+	server := httptest.NewServer(application.Handler())
+	t.Cleanup(func() {
+		server.Close()
+		application.Close()
+	})
+
+	return server
+}
+
+func doRequest(t *testing.T, method, url, token string, body interface{}) *http.Response {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(method, url, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func TestApp_EndToEnd(t *testing.T) {
+	server := newTestServer(t)
+	user := models.User{Username: "e2e", Password: "12345678"}
+
+	// Реєстрація та вхід
+	if resp := doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/register", "", user); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Реєстрація: отримано статус %d, очікувався %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	resp := doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/login", "", user)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Вхід: отримано статус %d, очікувався %d", resp.StatusCode, http.StatusOK)
+	}
+	token := resp.Header.Get("Authorization")
+	if token == "" {
+		t.Fatal("Вхід: токен не отримано")
+	}
+
+	// Без токена доступ до витрат заборонено
+	if resp := doRequest(t, http.MethodGet, server.URL+handlers.APIPrefix+"/expenses", "", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Без токена: отримано статус %d, очікувався %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	// Додавання та отримання витрати
+	expense := models.Expense{Category: "Food", Amount: 150}
+	if resp := doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/expenses", token, expense); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Додавання: отримано статус %d, очікувався %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	resp = doRequest(t, http.MethodGet, server.URL+handlers.APIPrefix+"/expenses", token, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Список: отримано статус %d, очікувався %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var expenses []models.Expense
+	if err := json.NewDecoder(resp.Body).Decode(&expenses); err != nil {
+		t.Fatal(err)
+	}
+	if len(expenses) != 1 || expenses[0].Category != "Food" || expenses[0].Amount != 150 {
+		t.Errorf("Отримано витрати %+v, очікувалася одна витрата Food/150", expenses)
+	}
+}
+
+func TestApp_TokenSignedWithConfiguredSecret(t *testing.T) {
+	server := newTestServer(t)
+	user := models.User{Username: "secret", Password: "12345678"}
+
+	doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/register", "", user)
+	token := doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/login", "", user).Header.Get("Authorization")
+
+	// Застосунок з іншим ключем не приймає токен
+	other := config.Default()
+	other.Auth.JWTSecret = "another-secret-another-secret-another"
+	otherServer := httptest.NewServer(New(other, database.NewMemoryStorage()).Handler())
+	defer otherServer.Close()
+
+	if resp := doRequest(t, http.MethodGet, otherServer.URL+handlers.APIPrefix+"/expenses", token, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Отримано статус %d, очікувався %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestApp_RequestIDPropagation(t *testing.T) {
+	server := newTestServer(t)
+	user := models.User{Username: "reqid", Password: "12345678"}
+
+	doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/register", "", user)
+	token := doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/login", "", user).Header.Get("Authorization")
+
+	// Ідентифікатор клієнта повертається у відповіді та потрапляє в історію змін
+	var payload bytes.Buffer
+	json.NewEncoder(&payload).Encode(models.Expense{Category: "Food", Amount: 10})
+	req, err := http.NewRequest(http.MethodPost, server.URL+handlers.APIPrefix+"/expenses", &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(middleware.RequestIDHeader, "client-req-1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get(middleware.RequestIDHeader); got != "client-req-1" {
+		t.Errorf("Отримано ідентифікатор запиту %q, очікувався %q", got, "client-req-1")
+	}
+
+	var expenses []models.Expense
+	if err := json.NewDecoder(doRequest(t, http.MethodGet, server.URL+handlers.APIPrefix+"/expenses", token, nil).Body).Decode(&expenses); err != nil || len(expenses) != 1 {
+		t.Fatalf("Отримано витрати %+v (%v), очікувалася одна", expenses, err)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("%s%s/expenses/%d/history", server.URL, handlers.APIPrefix, expenses[0].ID), token, nil)
+	var history []models.ExpenseRevision
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].RequestID != "client-req-1" {
+		t.Errorf("Отримано історію %+v, очікувався запис з request_id client-req-1", history)
+	}
+
+	// Без заголовка ідентифікатор генерується
+	if got := doRequest(t, http.MethodGet, server.URL+"/healthz", "", nil).Header.Get(middleware.RequestIDHeader); got == "" {
+		t.Error("Відповідь не містить згенерованого ідентифікатора запиту")
+	}
+}
+
+func TestApp_Routes(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		{"api route", http.MethodGet, handlers.APIPrefix + "/expenses", http.StatusUnauthorized, ""},
+		{"path parameter", http.MethodGet, handlers.APIPrefix + "/expenses/1", http.StatusUnauthorized, ""},
+		{"method not allowed", http.MethodDelete, handlers.APIPrefix + "/expenses", http.StatusMethodNotAllowed, "GET, HEAD, POST, PUT"},
+		{"login is post only", http.MethodGet, handlers.APIPrefix + "/login", http.StatusMethodNotAllowed, "POST"},
+		{"health is get only", http.MethodPost, "/healthz", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"unversioned path is not the api", http.MethodGet, "/expenses", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			resp := doRequest(t, tt.method, server.URL+tt.path, "", nil)
+
+			// Assert
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", resp.StatusCode, tt.expectedStatus)
+			}
+			if allow := resp.Header.Get("Allow"); allow != tt.expectedAllow {
+				t.Errorf("Отримано некоректний заголовок Allow: отримано %q, очікувалося %q", allow, tt.expectedAllow)
+			}
+		})
+	}
+}
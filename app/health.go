@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ChomuCake/uni-golang-labs/buildinfo"
+	"github.com/ChomuCake/uni-golang-labs/migration"
+)
+
+// Статуси перевірок у відповіді /readyz
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+// checkResult - результат однієї перевірки готовності
+type checkResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// readiness - тіло відповіді /readyz
+type readiness struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// check - перевірка готовності; nil означає, що перевірку пройдено
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// healthz повідомляє, що процес живий; залежності не перевіряються
+func (a *App) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": statusOK})
+}
+
+// readyz виконує всі перевірки готовності та повертає 200, якщо всі пройдено, інакше 503
+func (a *App) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if timeout := a.Config.Database.QueryTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	body := readiness{Status: statusOK, Checks: make(map[string]checkResult)}
+	for _, c := range a.readinessChecks() {
+		result := checkResult{Status: statusOK}
+		if err := c.run(ctx); err != nil {
+			result = checkResult{Status: statusFail, Error: err.Error()}
+			body.Status = statusFail
+		}
+		body.Checks[c.name] = result
+	}
+
+	status := http.StatusOK
+	if body.Status != statusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, body)
+}
+
+// readinessChecks повертає перевірки, доречні для поточного сховища.
+// Сховище в пам'яті не має СУБД і схеми, тому для нього перевіряється лише планувальник.
+func (a *App) readinessChecks() []check {
+	var checks []check
+
+	if conn := a.Storage.DB; conn != nil {
+		checks = append(checks,
+			check{name: "database", run: conn.PingContext},
+			check{name: "migrations", run: func(ctx context.Context) error {
+				pending, err := migration.Pending(ctx, conn, a.Storage.Driver)
+				if err != nil {
+					return err
+				}
+				if pending > 0 {
+					return fmt.Errorf("%d pending migration(s)", pending)
+				}
+				return nil
+			}},
+		)
+	}
+
+	if a.Scheduler != nil {
+		checks = append(checks, check{name: "scheduler", run: func(ctx context.Context) error {
+			if !a.Scheduler.Running() {
+				return fmt.Errorf("scheduler is not running")
+			}
+			return nil
+		}})
+	}
+
+	return checks
+}
+
+// version повертає версію збірки, коміт та версію Go (див. пакет buildinfo)
+func (a *App) version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildinfo.Get())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/buildinfo"
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/migration"
+	"github.com/ChomuCake/uni-golang-labs/scheduler"
+)
+
+func getReadiness(t *testing.T, handler http.Handler) (int, readiness) {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body readiness
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return rr.Code, body
+}
+
+func TestHealthz(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/healthz", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestReadyz_Scheduler(t *testing.T) {
+	// Arrange
+	application := New(config.Default(), database.NewMemoryStorage())
+	sched := scheduler.New()
+	application.Scheduler = sched
+
+	// Act: планувальник ще не запущено
+	status, body := getReadiness(t, application.Handler())
+
+	// Assert
+	if status != http.StatusServiceUnavailable || body.Checks["scheduler"].Status != statusFail {
+		t.Errorf("До запуску планувальника отримано %d %+v, очікувався 503", status, body)
+	}
+
+	// Act: після запуску
+	sched.Start()
+	defer sched.Stop()
+	status, body = getReadiness(t, application.Handler())
+
+	// Assert
+	if status != http.StatusOK || body.Status != statusOK {
+		t.Errorf("Після запуску планувальника отримано %d %+v, очікувався 200", status, body)
+	}
+	if _, ok := body.Checks["database"]; ok {
+		t.Error("Сховище в пам'яті не має перевірки database")
+	}
+}
+
+func TestReadyz_Migrations(t *testing.T) {
+	// Arrange
+	cfg := config.Default()
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.DSN = database.SQLiteDSN(filepath.Join(t.TempDir(), "ready.db"))
+
+	storage, err := database.Connect(t.Context(), cfg.Database)
+	if err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	application := New(cfg, storage)
+	defer application.Close()
+
+	// Act: схему ще не створено
+	status, body := getReadiness(t, application.Handler())
+
+	// Assert
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", status, http.StatusServiceUnavailable)
+	}
+	if body.Checks["database"].Status != statusOK || body.Checks["migrations"].Status != statusFail {
+		t.Errorf("Отримано перевірки %+v, очікувалися database=ok, migrations=fail", body.Checks)
+	}
+
+	// Act: після застосування міграцій
+	if _, err := migration.Up(t.Context(), storage.DB, storage.Driver); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	status, body = getReadiness(t, application.Handler())
+
+	// Assert
+	if status != http.StatusOK || body.Checks["migrations"].Status != statusOK {
+		t.Errorf("Після міграцій отримано %d %+v, очікувався 200", status, body)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/version", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v", resp.StatusCode, http.StatusOK)
+	}
+
+	var info buildinfo.Info
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != buildinfo.Version || info.GoVersion != runtime.Version() || info.Commit == "" {
+		t.Errorf("Отримано %+v, очікувалися версія %q та Go %q", info, buildinfo.Version, runtime.Version())
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+func TestMetrics_Endpoint(t *testing.T) {
+	server := newTestServer(t)
+	user := models.User{Username: "metrics", Password: "12345678"}
+
+	doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/register", "", user)
+	doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/login", "", user)
+	doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/login", "", models.User{Username: "metrics", Password: "wrong"})
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/metrics", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v", resp.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`http_requests_total{method="POST",route="/api/v1/login",status="200"} 1`,
+		`http_requests_total{method="POST",route="/api/v1/login",status="401"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/api/v1/register"} 1`,
+		`db_query_duration_seconds_count{method="AddUser",result="ok",store="users"} 1`,
+		`auth_logins_total{result="success"} 1`,
+		`auth_logins_total{result="failure"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Метрики не містять %s", want)
+		}
+	}
+}
+
+func TestMetrics_DBStats(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "0123456789abcdef0123456789abcdef"
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.DSN = database.SQLiteDSN(filepath.Join(t.TempDir(), "metrics.db"))
+
+	storage, err := database.Connect(t.Context(), cfg.Database)
+	if err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	application := New(cfg, storage)
+	defer application.Close()
+
+	rr := httptest.NewRecorder()
+	application.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := `go_sql_max_open_connections{db_name="sqlite"} 1`
+	if !strings.Contains(rr.Body.String(), want) {
+		t.Errorf("Метрики не містять %s", want)
+	}
+}
@@ -0,0 +1,309 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+
+	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/openapi"
+)
+
+// specValidator перевіряє кожен запит і відповідь тестового сервера на відповідність специфікації
+// та запам'ятовує перевірені операції
+type specValidator struct {
+	t      *testing.T
+	router routers.Router
+	next   http.Handler
+	seen   map[string]bool // "МЕТОД шлях" операцій специфікації
+}
+
+func newSpecValidator(t *testing.T, next http.Handler) *specValidator {
+	t.Helper()
+
+	doc, err := openapi.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &specValidator{t: t, router: router, next: next, seen: map[string]bool{}}
+}
+
+func (v *specValidator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		v.t.Fatal(err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		v.t.Errorf("%s %s: операцію не описано в специфікації: %v", r.Method, r.URL.Path, err)
+		v.next.ServeHTTP(w, r)
+		return
+	}
+	v.seen[r.Method+" "+route.Path] = true
+
+	rec := httptest.NewRecorder()
+	v.next.ServeHTTP(rec, r)
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r.Clone(context.Background()),
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			MultiError:         true,
+		},
+	}
+	input.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Запити з помилкою тести надсилають навмисно, тож специфікації має відповідати лише відповідь
+	if rec.Code < http.StatusBadRequest {
+		if err := openapi3filter.ValidateRequest(context.Background(), input); err != nil {
+			v.t.Errorf("%s %s: запит не відповідає специфікації: %v", r.Method, r.URL.Path, err)
+		}
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	if err := openapi3filter.ValidateResponse(context.Background(), responseInput); err != nil {
+		v.t.Errorf("%s %s: відповідь %d не відповідає специфікації: %v\n%s",
+			r.Method, r.URL.Path, rec.Code, err, rec.Body.String())
+	}
+
+	for key, values := range rec.Header() {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+// newSpecServer запускає застосунок, відповіді якого перевіряються на відповідність специфікації
+func newSpecServer(t *testing.T) (*httptest.Server, *specValidator) {
+	t.Helper()
+
+	inner := newTestServer(t)
+	validator := newSpecValidator(t, inner.Config.Handler)
+	server := httptest.NewServer(validator)
+	t.Cleanup(server.Close)
+
+	return server, validator
+}
+
+// doSpecRequest надсилає запит із довільними заголовками та сирим тілом
+func doSpecRequest(t *testing.T, method, url, token, body string, header map[string]string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	server, validator := newSpecServer(t)
+	api := server.URL + handlers.APIPrefix
+
+	steps := []struct {
+		name           string
+		method, path   string
+		body           string
+		header         map[string]string
+		expectedStatus int
+	}{
+		{"повторна реєстрація", "POST", "/register", `{"username": "spec", "password": "12345678"}`, nil, http.StatusConflict},
+		{"некоректна реєстрація", "POST", "/register", `{"username": "", "password": "1"}`, nil, http.StatusBadRequest},
+		{"неправильний пароль", "POST", "/login", `{"username": "spec", "password": "87654321"}`, nil, http.StatusUnauthorized},
+		{"без токена", "GET", "/expenses", "", map[string]string{"Authorization": ""}, http.StatusUnauthorized},
+		{"порожній перелік", "GET", "/expenses", "", nil, http.StatusOK},
+		{"додавання", "POST", "/expenses", `{"category": "food", "amount": 100}`, map[string]string{"Idempotency-Key": "spec-1"}, http.StatusCreated},
+		{"повтор додавання", "POST", "/expenses", `{"category": "food", "amount": 100}`, map[string]string{"Idempotency-Key": "spec-1"}, http.StatusCreated},
+		{"некоректна витрата", "POST", "/expenses", `{"category": "food", "amount": 0, "currency": "UAH"}`, nil, http.StatusBadRequest},
+		{"перелік", "GET", "/expenses?sort=all", "", nil, http.StatusOK},
+		{"без змін", "GET", "/expenses", "", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"сторінка", "GET", "/expenses?sort=all&category=food&limit=1&offset=0", "", nil, http.StatusOK},
+		{"некоректна сторінка", "GET", "/expenses?limit=0", "", nil, http.StatusBadRequest},
+		{"некоректне сортування", "GET", "/expenses?sort=year", "", nil, http.StatusMisdirectedRequest},
+		{"витрата", "GET", "/expenses/1", "", nil, http.StatusOK},
+		{"некоректний id", "GET", "/expenses/abc", "", nil, http.StatusBadRequest},
+		{"неіснуюча витрата", "GET", "/expenses/100", "", nil, http.StatusNotFound},
+		{"заміна без If-Match", "PUT", "/expenses/1", `{"category": "rent", "amount": 200, "rawdate": "2024-05-27"}`, nil, http.StatusPreconditionRequired},
+		{"заміна", "PUT", "/expenses/1", `{"category": "rent", "amount": 200, "rawdate": "2024-05-27"}`, map[string]string{"If-Match": `"1"`}, http.StatusOK},
+		{"заміна за id у тілі", "PUT", "/expenses", `{"id": 1, "category": "rent", "amount": 300, "rawdate": "2024-05-27"}`, map[string]string{"If-Match": `"2"`}, http.StatusOK},
+		{"застаріла версія", "PUT", "/expenses/1", `{"category": "rent", "amount": 200, "rawdate": "2024-05-27"}`, map[string]string{"If-Match": `"1"`}, http.StatusPreconditionFailed},
+		{"часткова зміна", "PATCH", "/expenses/1", `{"amount": 250}`, map[string]string{"If-Match": `"3"`, "Content-Type": "application/merge-patch+json"}, http.StatusOK},
+		{"некоректна часткова зміна", "PATCH", "/expenses/1", `{"id": 5, "category": null}`, map[string]string{"If-Match": `"4"`, "Content-Type": "application/merge-patch+json"}, http.StatusBadRequest},
+		{"історія", "GET", "/expenses/1/history", "", nil, http.StatusOK},
+		{"відкат", "POST", "/expenses/1/history/1/revert", "", nil, http.StatusOK},
+		{"пакет", "POST", "/expenses/batch", `{"mode": "partial", "operations": [
+			{"op": "create", "expense": {"category": "taxi", "amount": 50}},
+			{"op": "delete", "id": 100, "version": 1}
+		]}`, nil, http.StatusMultiStatus},
+		{"атомарний пакет", "POST", "/expenses/batch", `{"operations": [
+			{"op": "update", "id": 2, "version": 7, "expense": {"category": "taxi", "amount": 60, "rawdate": "2024-05-28"}}
+		]}`, nil, http.StatusUnprocessableEntity},
+		{"видалення", "DELETE", "/expenses/2", "", map[string]string{"If-Match": `"1"`}, http.StatusOK},
+		{"кошик", "GET", "/expenses/trash", "", nil, http.StatusOK},
+		{"відновлення", "POST", "/expenses/trash/2/restore", "", nil, http.StatusOK},
+		{"повторне видалення", "DELETE", "/expenses/2", "", map[string]string{"If-Match": `"3"`}, http.StatusOK},
+		{"остаточне видалення", "DELETE", "/expenses/trash/2", "", nil, http.StatusOK},
+		{"порожній кошик", "GET", "/expenses/trash", "", nil, http.StatusOK},
+	}
+
+	// Вхід виконується через сервер, тож його відповідь теж перевіряється
+	if resp := doSpecRequest(t, "POST", api+"/register", "", `{"username": "spec", "password": "12345678"}`, nil); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Реєстрація: отримано статус %d, очікувався %d", resp.StatusCode, http.StatusCreated)
+	}
+	resp := doSpecRequest(t, "POST", api+"/login", "", `{"username": "spec", "password": "12345678"}`, nil)
+	token := resp.Header.Get("Authorization")
+	if token == "" {
+		t.Fatal("Вхід: токен не отримано")
+	}
+
+	for _, step := range steps {
+		resp := doSpecRequest(t, step.method, api+step.path, token, step.body, step.header)
+		if resp.StatusCode != step.expectedStatus {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("%s: отримано статус %d, очікувався %d\n%s", step.name, resp.StatusCode, step.expectedStatus, body)
+		}
+	}
+
+	for _, path := range []string{"/healthz", "/readyz", "/version", "/metrics"} {
+		if resp := doSpecRequest(t, "GET", server.URL+path, "", "", nil); resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s: отримано статус %d, очікувався %d", path, resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	// Кожна операція специфікації має бути перевірена хоча б одним запитом
+	doc, err := openapi.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !validator.seen[method+" "+path] {
+				t.Errorf("Операцію %s %s не перевірено тестом", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPI_DescribesAllRoutes(t *testing.T) {
+	// Arrange
+	doc, err := openapi.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"GET /healthz": true,
+		"GET /readyz":  true,
+		"GET /version": true,
+		"GET /metrics": true,
+	}
+	for _, routes := range [][]handlers.Route{(&handlers.UserHandler{}).Routes(), (&handlers.ExpenseHandler{}).Routes()} {
+		for _, route := range routes {
+			expected[route.Method()+" "+handlers.APIPrefix+route.Path()] = true
+		}
+	}
+
+	// Act
+	described := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			described[method+" "+path] = true
+		}
+	}
+
+	// Assert
+	for operation := range expected {
+		if !described[operation] {
+			t.Errorf("Маршрут %s не описано в специфікації", operation)
+		}
+	}
+	for operation := range described {
+		if !expected[operation] {
+			t.Errorf("Операція специфікації %s не має маршруту", operation)
+		}
+	}
+}
+
+func TestOpenAPI_Endpoints(t *testing.T) {
+	server := newTestServer(t)
+
+	// Специфікація у форматі JSON
+	resp := doSpecRequest(t, "GET", server.URL+openapi.SpecPath, "", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v", resp.StatusCode, http.StatusOK)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Отримано Content-Type %q, очікувався application/json", contentType)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromIoReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths.Find(handlers.APIPrefix+"/expenses") == nil {
+		t.Error("Опублікована специфікація не містить /api/v1/expenses")
+	}
+
+	// Сторінка Swagger UI
+	resp = doSpecRequest(t, "GET", server.URL+openapi.UIPath, "", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v", resp.StatusCode, http.StatusOK)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	if !bytes.Contains(page, []byte(fmt.Sprintf("%q", openapi.SpecPath))) {
+		t.Error("Сторінка Swagger UI не посилається на специфікацію")
+	}
+
+	// Сторінка завантажує Swagger UI точної версії, а політика безпеки не дозволяє інших скриптів
+	if bytes.Contains(page, []byte("swagger-ui-dist@5/")) {
+		t.Error("Сторінка Swagger UI завантажує рухому версію swagger-ui-dist@5")
+	}
+	policy := resp.Header.Get("Content-Security-Policy")
+	if !strings.Contains(policy, "script-src 'sha256-") || strings.Contains(policy, "'unsafe-eval'") {
+		t.Errorf("Отримано некоректну політику безпеки сторінки Swagger UI: %q", policy)
+	}
+}
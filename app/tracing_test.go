@@ -0,0 +1,76 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing_RequestSpans(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	server := newTestServer(t)
+	user := models.User{Username: "traced", Password: "12345678"}
+	doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/register", "", user)
+	token := doRequest(t, http.MethodPost, server.URL+handlers.APIPrefix+"/login", "", user).Header.Get("Authorization")
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, err := http.NewRequest(http.MethodGet, server.URL+handlers.APIPrefix+"/expenses", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	// Act
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Assert: спан запиту, перевірки токена та операцій сховищ - в одній трасі клієнта
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() == traceID {
+			spans[span.Name()] = span
+		}
+	}
+
+	root, ok := spans["GET /api/v1/expenses"]
+	if !ok {
+		t.Fatalf("Немає спану HTTP-запиту; отримано %v", names(spans))
+	}
+	for _, name := range []string{"auth.VerifyToken", "users.GetUserByID", "expenses.GetUserExpenses"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("Немає спану %s; отримано %v", name, names(spans))
+			continue
+		}
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("Спан %s не є дочірнім для спану запиту", name)
+		}
+	}
+}
+
+func names(spans map[string]sdktrace.ReadOnlySpan) []string {
+	var result []string
+	for name := range spans {
+		result = append(result, name)
+	}
+	return result
+}
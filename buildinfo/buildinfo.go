@@ -0,0 +1,52 @@
+// Package buildinfo містить відомості про збірку, що підставляються під час компіляції:
+//
+//	go build -ldflags "-X github.com/ChomuCake/uni-golang-labs/buildinfo.Version=v1.2.0 \
+//	  -X github.com/ChomuCake/uni-golang-labs/buildinfo.Commit=$(git rev-parse HEAD)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Значення, що задаються через -ldflags "-X ..."
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Info - відомості про збірку, що повертає /version
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get повертає відомості про поточну збірку. Якщо коміт не задано через -ldflags,
+// береться ревізія VCS, яку go build записує у бінарний файл.
+func Get() Info {
+	commit := Commit
+	if commit == "" {
+		commit = vcsRevision()
+	}
+
+	return Info{
+		Version:   Version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+	}
+}
+
+func vcsRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return "unknown"
+}
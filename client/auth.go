@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// tokenRefreshMargin - токен оновлюється заздалегідь, якщо до закінчення строку його дії лишилося менше
+const tokenRefreshMargin = 30 * time.Second
+
+// Register реєструє користувача; вхід після реєстрації виконується окремо через Login
+func (c *Client) Register(ctx context.Context, username, password string) error {
+	_, err := c.do(ctx, request{
+		method:    http.MethodPost,
+		path:      "/register",
+		body:      models.User{Username: username, Password: password},
+		anonymous: true,
+	}, nil)
+	return err
+}
+
+// Login входить у систему та запам'ятовує токен. Ім'я та пароль зберігаються в пам'яті клієнта
+// до наступного Login чи SetToken: сервер не видає токенів оновлення, тож прострочений токен
+// клієнт замінює повторним входом з ними (див. опис пакета).
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	_, err := c.login(ctx, models.User{Username: username, Password: password})
+	return err
+}
+
+// SetToken встановлює отриманий раніше токен (напр. збережений у файлі).
+// Облікові дані не відомі, тож прострочений токен не оновлюється і запити повертають ErrUnauthorized.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = token
+	c.credentials = nil
+}
+
+// Token повертає поточний токен (порожній рядок, якщо вхід не виконано)
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token
+}
+
+func (c *Client) login(ctx context.Context, user models.User) (string, error) {
+	resp, err := c.do(ctx, request{
+		method:    http.MethodPost,
+		path:      "/login",
+		body:      user,
+		anonymous: true,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimPrefix(resp.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return "", errors.New("client: login response has no token")
+	}
+
+	c.mu.Lock()
+	c.token = token
+	c.credentials = &user
+	onToken := c.OnToken
+	c.mu.Unlock()
+
+	if onToken != nil {
+		onToken(token)
+	}
+	return token, nil
+}
+
+// authToken повертає токен для запиту, заздалегідь оновлюючи його, якщо строк дії спливає
+func (c *Client) authToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, credentials := c.token, c.credentials
+	c.mu.Unlock()
+
+	if credentials != nil {
+		if expiry, ok := tokenExpiry(token); !ok || time.Until(expiry) < tokenRefreshMargin {
+			return c.login(ctx, *credentials)
+		}
+	}
+	return token, nil
+}
+
+func (c *Client) canRefresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.credentials != nil
+}
+
+// refreshToken повторно входить збереженими обліковими даними
+func (c *Client) refreshToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	credentials := c.credentials
+	c.mu.Unlock()
+
+	if credentials == nil {
+		return "", ErrUnauthorized
+	}
+	return c.login(ctx, *credentials)
+}
+
+// tokenExpiry читає час закінчення дії (claim exp) з корисного навантаження JWT без перевірки підпису -
+// підпис перевіряє сервер, клієнту потрібно лише знати, коли оновити токен
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
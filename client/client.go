@@ -0,0 +1,279 @@
+// Package client - типізований Go-клієнт REST API обліку витрат, що використовує спільні з сервером типи models.
+//
+//	c := client.New("http://localhost:8080")
+//	if err := c.Login(ctx, "john", "secret-password"); err != nil {
+//		return err
+//	}
+//	expenses, err := c.Expenses.List(ctx, client.ListFilter{Sort: "month"})
+//
+// Клієнт повторює запити після мережевих помилок та відповідей 429, 502, 503 і 504 з експоненційною затримкою.
+// Повторюються лише безпечні запити: GET, PUT, DELETE та запити з заголовком Idempotency-Key, який отримують
+// усі змінюючі запити до витрат, тож повтор не застосовує зміну двічі. Реєстрація та вхід не повторюються.
+//
+// Сервер не має окремого ендпоінта оновлення токена, тому після Login клієнт зберігає ім'я та пароль
+// у пам'яті процесу до наступного Login чи SetToken і повторно входить ними: заздалегідь, коли строк дії
+// токена спливає, та після відповіді 401. Якщо пароль не повинен залишатися в пам'яті, отримайте токен
+// через Login, передайте його новому клієнту через SetToken і виконуйте вхід заново, коли запити
+// повертають ErrUnauthorized.
+//
+// Помилки API повертаються як *Error; їх можна перевіряти через errors.Is з ErrNotFound, ErrUnauthorized тощо.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// APIPrefix - префікс версії REST API, з якою працює клієнт (див. handlers.APIPrefix)
+const APIPrefix = "/api/v1"
+
+// Типові налаштування повторів
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// maxResponseBytes обмежує тіло відповіді з помилкою, що читається клієнтом
+const maxResponseBytes = 1 << 20
+
+// Client - клієнт API. Налаштування (HTTPClient, MaxRetries тощо) можна змінити до першого запиту;
+// методи безпечні для одночасного використання з кількох горутин.
+type Client struct {
+	// BaseURL - адреса сервера без префікса API, напр. http://localhost:8080
+	BaseURL string
+	// HTTPClient виконує запити; за замовчуванням http.DefaultClient
+	HTTPClient *http.Client
+	// UserAgent надсилається в заголовку User-Agent
+	UserAgent string
+
+	// MaxRetries - кількість повторів після першої спроби; 0 вимикає повтори
+	MaxRetries int
+	// MinBackoff та MaxBackoff обмежують затримку перед повтором, що подвоюється з кожною спробою
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// OnToken викликається з новим токеном після кожного входу, зокрема автоматичного оновлення
+	// (напр. щоб зберегти токен у файлі налаштувань)
+	OnToken func(token string)
+
+	Expenses *ExpensesService
+
+	mu          sync.Mutex
+	token       string
+	credentials *models.User // Облікові дані останнього Login для оновлення токена
+}
+
+// New створює клієнт сервера за адресою baseURL
+func New(baseURL string) *Client {
+	c := &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		UserAgent:  "finance-tracker-client",
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+	c.Expenses = &ExpensesService{client: c}
+	return c
+}
+
+// request - опис запиту до API
+type request struct {
+	method      string
+	path        string // Шлях відносно APIPrefix
+	query       url.Values
+	header      http.Header
+	body        any
+	contentType string // За замовчуванням application/json
+	anonymous   bool   // Запит не потребує токена (реєстрація та вхід)
+	accept      []int  // Статуси помилок, тіло яких розбирається в out, а не в *Error
+}
+
+// do виконує запит із повторами та оновленням токена і розбирає JSON-відповідь в out (якщо out не nil).
+// Тіло відповіді вже прочитане й закрите; повертається для доступу до статусу та заголовків.
+func (c *Client) do(ctx context.Context, req request, out any) (*http.Response, error) {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("client: encode request: %w", err)
+		}
+	}
+
+	header := http.Header{}
+	for key, values := range req.header {
+		header[key] = values
+	}
+	if payload != nil {
+		contentType := req.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Accept", "application/json, application/problem+json")
+	if c.UserAgent != "" {
+		header.Set("User-Agent", c.UserAgent)
+	}
+
+	// Змінюючі запити до витрат сервер виконує не більше одного разу для одного ключа
+	retrySafe := req.method == http.MethodGet || req.method == http.MethodPut || req.method == http.MethodDelete
+	if !req.anonymous && req.method != http.MethodGet && header.Get("Idempotency-Key") == "" {
+		header.Set("Idempotency-Key", rand.Text())
+	}
+	if header.Get("Idempotency-Key") != "" {
+		retrySafe = true
+	}
+
+	target := c.BaseURL + APIPrefix + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("client: %w", err)
+		}
+		httpReq.Header = header.Clone()
+
+		if !req.anonymous {
+			token, err := c.authToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if token != "" {
+				httpReq.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		resp, err := c.HTTPClient.Do(httpReq)
+		if err != nil {
+			// Запит міг дійти до сервера, тож повторюється лише безпечний
+			if ctx.Err() != nil || !retrySafe || attempt >= c.MaxRetries {
+				return nil, fmt.Errorf("client: %s %s: %w", req.method, req.path, err)
+			}
+			if err := sleep(ctx, c.backoff(attempt, 0)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Прострочений або відкликаний токен: повторний вхід і одна повторна спроба
+		if resp.StatusCode == http.StatusUnauthorized && !req.anonymous && !refreshed && c.canRefresh() {
+			discard(resp)
+			refreshed = true
+			attempt--
+			if _, err := c.refreshToken(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Відповідь 502 чи 504 могла прийти від проксі вже після виконання запиту сервером,
+		// тож за статусом, як і після мережевої помилки, повторюється лише безпечний запит
+		if retryableStatus(resp.StatusCode) && retrySafe && attempt < c.MaxRetries {
+			delay := c.backoff(attempt, retryAfter(resp.Header))
+			discard(resp)
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return resp, c.readResponse(resp, req, out)
+	}
+}
+
+// readResponse розбирає тіло відповіді та закриває його
+func (c *Client) readResponse(resp *http.Response, req request, out any) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest && !slices.Contains(req.accept, resp.StatusCode) {
+		return newError(resp, req)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNotModified || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: %s %s: decode response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// retryableStatus повідомляє, чи означає статус тимчасову недоступність сервера
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff повертає затримку перед повтором attempt: Retry-After сервера або MinBackoff·2^attempt
+// з випадковим розкидом у межах половини, щоб клієнти не повторювали запити одночасно
+func (c *Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, c.MaxBackoff)
+	}
+
+	delay := c.MinBackoff << attempt
+	if delay <= 0 || delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + mathrand.N(delay/2+1)
+}
+
+// retryAfter розбирає заголовок Retry-After (секунди або дата HTTP)
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// discard дочитує та закриває тіло, щоб з'єднання повернулося в пул
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	resp.Body.Close()
+}
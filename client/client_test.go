@@ -0,0 +1,439 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/app"
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// newTestServer запускає справжній застосунок зі сховищем у пам'яті; wrap (якщо задано) обгортає його обробник
+func newTestServer(t *testing.T, tokenTTL time.Duration, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "0123456789abcdef0123456789abcdef"
+	cfg.Database.Driver = database.DriverMemory
+	cfg.Auth.TokenTTL = tokenTTL
+
+	application := app.New(cfg, database.NewMemoryStorage())
+	handler := application.Handler()
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		server.Close()
+		application.Close()
+	})
+
+	return server
+}
+
+// newLoggedInClient реєструє користувача та повертає клієнт після входу
+func newLoggedInClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper()
+
+	c := New(server.URL)
+	c.MinBackoff = time.Millisecond
+	c.MaxBackoff = 10 * time.Millisecond
+
+	if err := c.Register(t.Context(), "client", "12345678"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Login(t.Context(), "client", "12345678"); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClient_APIPrefix(t *testing.T) {
+	if APIPrefix != handlers.APIPrefix {
+		t.Errorf("Префікс клієнта %q не збігається з префіксом сервера %q", APIPrefix, handlers.APIPrefix)
+	}
+}
+
+func TestClient_ExpenseLifecycle(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, time.Minute, nil)
+	c := newLoggedInClient(t, server)
+	ctx := t.Context()
+
+	// Act & Assert: створення
+	created, err := c.Expenses.Create(ctx, models.Expense{Category: "food", Amount: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == 0 || created.Version != 1 || created.Category != "food" {
+		t.Fatalf("Отримано некоректну створену витрату: %+v", created)
+	}
+
+	// Заміна та часткова зміна повертають нову версію
+	created.Category, created.Amount, created.RawDate = "rent", 200, "2024-05-27"
+	updated, err := c.Expenses.Update(ctx, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 2 || updated.Amount != 200 {
+		t.Errorf("Отримано некоректну замінену витрату: %+v", updated)
+	}
+
+	patched, err := c.Expenses.Patch(ctx, updated.ID, updated.Version, map[string]any{"amount": 250})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.Version != 3 || patched.Amount != 250 || patched.Category != "rent" {
+		t.Errorf("Отримано некоректну змінену витрату: %+v", patched)
+	}
+
+	// Застаріла версія
+	if _, err := c.Expenses.Update(ctx, updated); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Отримано помилку %v, очікувалася ErrVersionConflict", err)
+	}
+
+	// Історія та відкат до першої ревізії
+	history, err := c.Expenses.History(ctx, patched.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("Отримано %d ревізій, очікувалося 3", len(history))
+	}
+	reverted, err := c.Expenses.Revert(ctx, patched.ID, history[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted.Category != "food" || reverted.Amount != 100 {
+		t.Errorf("Отримано некоректну витрату після відкату: %+v", reverted)
+	}
+
+	// Кошик: видалення, відновлення, остаточне видалення
+	if err := c.Expenses.Delete(ctx, reverted.ID, reverted.Version); err != nil {
+		t.Fatal(err)
+	}
+	trash, err := c.Expenses.Trash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != reverted.ID {
+		t.Errorf("Отримано некоректний кошик: %+v", trash)
+	}
+	if err := c.Expenses.Restore(ctx, reverted.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := c.Expenses.Get(ctx, reverted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Expenses.Delete(ctx, restored.ID, restored.Version); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Expenses.Purge(ctx, restored.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Expenses.Get(ctx, restored.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Отримано помилку %v, очікувалася ErrNotFound", err)
+	}
+}
+
+func TestClient_Batch(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, time.Minute, nil)
+	c := newLoggedInClient(t, server)
+
+	// Act
+	result, err := c.Expenses.Batch(t.Context(), models.BatchRequest{
+		Operations: []models.BatchOperation{
+			{Op: models.BatchCreate, Expense: models.Expense{Category: "food", Amount: 10}},
+			{Op: models.BatchDelete, ID: 100, Version: 1},
+		},
+	})
+
+	// Assert: атомарний пакет із помилкою скасовано, але це не помилка виклику
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Committed || len(result.Results) != 2 || result.Results[1].Status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний результат пакета: %+v", result)
+	}
+}
+
+func TestClient_ValidationError(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, time.Minute, nil)
+	c := newLoggedInClient(t, server)
+
+	// Act
+	_, err := c.Expenses.Create(t.Context(), models.Expense{Category: "food"})
+
+	// Assert
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Отримано помилку %v, очікувалася *Error зі статусом 400", err)
+	}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "amount" || apiErr.RequestID == "" {
+		t.Errorf("Отримано некоректну помилку: %+v", apiErr)
+	}
+}
+
+func TestClient_Pagination(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, time.Minute, nil)
+	c := newLoggedInClient(t, server)
+	ctx := t.Context()
+
+	for _, category := range []string{"food", "rent", "food", "food", "taxi", "food"} {
+		if _, err := c.Expenses.Create(ctx, models.Expense{Category: category, Amount: 10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Act
+	var pageSizes []int
+	for page, err := range c.Expenses.Pages(ctx, ListFilter{Category: "food", Limit: 3}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		pageSizes = append(pageSizes, len(page))
+	}
+
+	var ids []int
+	for expense, err := range c.Expenses.All(ctx, ListFilter{Limit: 4}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, expense.ID)
+	}
+
+	// Assert: 4 витрати food - повна сторінка та неповна, без зайвого запиту
+	if !slices.Equal(pageSizes, []int{3, 1}) {
+		t.Errorf("Отримано сторінки розміром %v, очікувалося [3 1]", pageSizes)
+	}
+	if !slices.Equal(ids, []int{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("Отримано витрати %v, очікувалося [1 2 3 4 5 6]", ids)
+	}
+
+	// Перебір можна перервати
+	for range c.Expenses.All(ctx, ListFilter{Limit: 2}) {
+		break
+	}
+}
+
+func TestClient_RefreshesRejectedToken(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, time.Minute, nil)
+	c := newLoggedInClient(t, server)
+
+	var tokens []string
+	c.OnToken = func(token string) { tokens = append(tokens, token) }
+	c.mu.Lock()
+	c.token = "revoked"
+	c.mu.Unlock()
+
+	// Act
+	_, err := c.Expenses.List(t.Context(), ListFilter{})
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || c.Token() != tokens[0] {
+		t.Errorf("Токен не оновлено після відповіді 401: %v", tokens)
+	}
+}
+
+func TestClient_RefreshesExpiringToken(t *testing.T) {
+	// Arrange: строк дії токена менший за запас оновлення, тож кожен запит спочатку оновлює токен
+	server := newTestServer(t, 10*time.Second, nil)
+	c := newLoggedInClient(t, server)
+
+	logins := 0
+	c.OnToken = func(string) { logins++ }
+
+	// Act
+	if _, err := c.Expenses.List(t.Context(), ListFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if logins != 1 {
+		t.Errorf("Отримано %d входів, очікувався 1", logins)
+	}
+}
+
+func TestClient_TokenWithoutCredentials(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, time.Minute, nil)
+	newLoggedInClient(t, server)
+
+	c := New(server.URL)
+	c.SetToken("revoked")
+
+	// Act
+	_, err := c.Expenses.List(t.Context(), ListFilter{})
+
+	// Assert
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Отримано помилку %v, очікувалася ErrUnauthorized", err)
+	}
+}
+
+// flaky відповідає 503 на перші failures запитів до шляху path і запам'ятовує їхні ключі ідемпотентності
+type flaky struct {
+	mu       sync.Mutex
+	path     string
+	failures int
+	keys     []string
+	next     http.Handler
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == f.path {
+		f.mu.Lock()
+		f.keys = append(f.keys, r.Header.Get("Idempotency-Key"))
+		fail := len(f.keys) <= f.failures
+		f.mu.Unlock()
+
+		if fail {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func TestClient_RetriesWithSameIdempotencyKey(t *testing.T) {
+	// Arrange
+	proxy := &flaky{path: APIPrefix + "/expenses", failures: 2}
+	server := newTestServer(t, time.Minute, func(next http.Handler) http.Handler {
+		proxy.next = next
+		return proxy
+	})
+	c := newLoggedInClient(t, server)
+
+	// Act
+	created, err := c.Expenses.Create(t.Context(), models.Expense{Category: "food", Amount: 10})
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 1 {
+		t.Errorf("Отримано витрату %+v, очікувалася витрата з ID 1", created)
+	}
+	if len(proxy.keys) != 3 || proxy.keys[0] == "" || proxy.keys[0] != proxy.keys[1] || proxy.keys[1] != proxy.keys[2] {
+		t.Errorf("Отримано ключі ідемпотентності %q, очікувалися три однакові", proxy.keys)
+	}
+}
+
+func TestClient_GivesUpAfterMaxRetries(t *testing.T) {
+	// Arrange
+	proxy := &flaky{path: APIPrefix + "/expenses", failures: 100}
+	server := newTestServer(t, time.Minute, func(next http.Handler) http.Handler {
+		proxy.next = next
+		return proxy
+	})
+	c := newLoggedInClient(t, server)
+	c.MaxRetries = 2
+
+	// Act
+	_, err := c.Expenses.List(t.Context(), ListFilter{})
+
+	// Assert
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Отримано помилку %v, очікувалася *Error зі статусом 503", err)
+	}
+	if !strings.Contains(apiErr.Detail, "unavailable") {
+		t.Errorf("Отримано опис %q, очікувався текст відповіді", apiErr.Detail)
+	}
+	if len(proxy.keys) != 3 {
+		t.Errorf("Отримано %d спроб, очікувалося 3", len(proxy.keys))
+	}
+}
+
+func TestClient_DoesNotRetryUnsafeRequests(t *testing.T) {
+	// Arrange
+	proxy := &flaky{path: APIPrefix + "/register", failures: 100}
+	server := newTestServer(t, time.Minute, func(next http.Handler) http.Handler {
+		proxy.next = next
+		return proxy
+	})
+	c := New(server.URL)
+	c.MinBackoff = time.Millisecond
+
+	// Act
+	err := c.Register(t.Context(), "client", "12345678")
+
+	// Assert
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Отримано помилку %v, очікувалася *Error зі статусом 503", err)
+	}
+	if len(proxy.keys) != 1 {
+		t.Errorf("Отримано %d спроб, очікувалася 1: запит без ключа ідемпотентності не повторюється", len(proxy.keys))
+	}
+}
+
+func TestClient_BackoffStopsOnContextCancel(t *testing.T) {
+	// Arrange
+	proxy := &flaky{path: APIPrefix + "/expenses", failures: 100}
+	server := newTestServer(t, time.Minute, func(next http.Handler) http.Handler {
+		proxy.next = next
+		return proxy
+	})
+	c := newLoggedInClient(t, server)
+	c.MinBackoff, c.MaxBackoff = time.Minute, time.Minute
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := c.Expenses.List(ctx, ListFilter{})
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Отримано помилку %v, очікувалася context.DeadlineExceeded", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := New("http://localhost")
+	c.MinBackoff, c.MaxBackoff = 100*time.Millisecond, time.Second
+
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		min, max   time.Duration
+	}{
+		{0, 0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 0, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 0, 500 * time.Millisecond, time.Second},
+		{0, 300 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond},
+		{0, time.Hour, time.Second, time.Second},
+	}
+
+	for _, tt := range tests {
+		// Act
+		delay := c.backoff(tt.attempt, tt.retryAfter)
+
+		// Assert
+		if delay < tt.min || delay > tt.max {
+			t.Errorf("Спроба %d: отримано затримку %v, очікувалася від %v до %v", tt.attempt, delay, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// Помилки для перевірки *Error через errors.Is
+var (
+	ErrUnauthorized    = errors.New("client: unauthorized")     // 401: вхід не виконано або токен недійсний
+	ErrNotFound        = errors.New("client: not found")        // 404: витрата не існує або належить іншому користувачу
+	ErrConflict        = errors.New("client: conflict")         // 409: напр. ім'я користувача вже зайняте
+	ErrVersionConflict = errors.New("client: version conflict") // 412: витрату змінено після отримання її версії
+	ErrInvalid         = errors.New("client: invalid request")  // 400: Fields містить помилки окремих полів
+)
+
+// Error - помилка API: відповідь із проблемою RFC 7807 (див. handlers.Problem)
+type Error struct {
+	StatusCode int                     `json:"status"`
+	Type       string                  `json:"type"`
+	Title      string                  `json:"title"`
+	Detail     string                  `json:"detail"`
+	Instance   string                  `json:"instance"`
+	RequestID  string                  `json:"request_id"` // Для пошуку запиту в журналі сервера
+	Fields     []validation.FieldError `json:"errors"`
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "api: %d %s", e.StatusCode, e.Title)
+	if e.Detail != "" {
+		b.WriteString(": " + e.Detail)
+	}
+	if len(e.Fields) > 0 {
+		fields := make([]string, len(e.Fields))
+		for i, field := range e.Fields {
+			fields[i] = field.Field + " " + field.Message
+		}
+		b.WriteString(" (" + strings.Join(fields, "; ") + ")")
+	}
+	return b.String()
+}
+
+// Is зіставляє статус помилки з ErrUnauthorized, ErrNotFound, ErrConflict, ErrVersionConflict та ErrInvalid
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrVersionConflict:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest
+	}
+	return false
+}
+
+// newError розбирає відповідь з помилкою; тіло, що не є проблемою JSON, стає Detail
+func newError(resp *http.Response, req request) error {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("client: %s %s: read response: %w", req.method, req.path, err)
+	}
+
+	apiErr := &Error{}
+	if json.Unmarshal(body, apiErr) != nil {
+		apiErr = &Error{Detail: strings.TrimSpace(string(body))}
+	}
+	apiErr.StatusCode = resp.StatusCode
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// DefaultPageSize - розмір сторінки для Pages та All, якщо ListFilter.Limit не задано
+const DefaultPageSize = 100
+
+// ListFilter - параметри переліку витрат; нульові поля не звужують перелік
+type ListFilter struct {
+	Sort     string // day - сьогоднішні, month - за поточний місяць, all (або порожньо) - усі
+	Category string // Категорія без урахування регістру
+	From, To string // Межі дат включно, YYYY-MM-DD
+	Limit    int    // Розмір сторінки; 0 - усі витрати
+	Offset   int
+}
+
+func (f ListFilter) query() url.Values {
+	query := url.Values{}
+	for name, value := range map[string]string{"sort": f.Sort, "category": f.Category, "from": f.From, "to": f.To} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if f.Limit > 0 {
+		query.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.Offset > 0 {
+		query.Set("offset", strconv.Itoa(f.Offset))
+	}
+	return query
+}
+
+// ExpensesService - операції з витратами, кошиком та історією змін
+type ExpensesService struct {
+	client *Client
+}
+
+// List повертає витрати, що відповідають фільтру (одну сторінку, якщо задано filter.Limit)
+func (s *ExpensesService) List(ctx context.Context, filter ListFilter) ([]models.Expense, error) {
+	expenses, _, err := s.list(ctx, filter)
+	return expenses, err
+}
+
+func (s *ExpensesService) list(ctx context.Context, filter ListFilter) ([]models.Expense, int, error) {
+	var expenses []models.Expense
+	resp, err := s.client.do(ctx, request{method: http.MethodGet, path: "/expenses", query: filter.query()}, &expenses)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := strconv.Atoi(resp.Header.Get("X-Total-Count"))
+	if err != nil {
+		total = filter.Offset + len(expenses)
+	}
+	return expenses, total, nil
+}
+
+// Pages перебирає сторінки витрат, що відповідають фільтру, починаючи з filter.Offset.
+// Розмір сторінки - filter.Limit або DefaultPageSize. Помилка завершує перебір.
+//
+// Сторінки запитуються по черзі, тож витрати, додані чи видалені під час перебору, можуть бути пропущені або повторені.
+func (s *ExpensesService) Pages(ctx context.Context, filter ListFilter) iter.Seq2[[]models.Expense, error] {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+
+	return func(yield func([]models.Expense, error) bool) {
+		for {
+			page, total, err := s.list(ctx, filter)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(page) == 0 || !yield(page, nil) {
+				return
+			}
+
+			filter.Offset += len(page)
+			if len(page) < filter.Limit || filter.Offset >= total {
+				return
+			}
+		}
+	}
+}
+
+// All перебирає всі витрати, що відповідають фільтру, запитуючи їх сторінками (див. Pages)
+func (s *ExpensesService) All(ctx context.Context, filter ListFilter) iter.Seq2[models.Expense, error] {
+	return func(yield func(models.Expense, error) bool) {
+		for page, err := range s.Pages(ctx, filter) {
+			if err != nil {
+				yield(models.Expense{}, err)
+				return
+			}
+			for _, expense := range page {
+				if !yield(expense, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Get повертає витрату разом з її поточною версією
+func (s *ExpensesService) Get(ctx context.Context, id int) (models.Expense, error) {
+	var expense models.Expense
+	_, err := s.client.do(ctx, request{method: http.MethodGet, path: expensePath(id)}, &expense)
+	return expense, err
+}
+
+// Create додає витрату (сервер датує її поточним часом) і повертає збережену витрату
+func (s *ExpensesService) Create(ctx context.Context, expense models.Expense) (models.Expense, error) {
+	resp, err := s.client.do(ctx, request{method: http.MethodPost, path: "/expenses", body: expense}, nil)
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	id, err := strconv.Atoi(path.Base(resp.Header.Get("Location")))
+	if err != nil {
+		return models.Expense{}, fmt.Errorf("client: create expense: invalid Location %q", resp.Header.Get("Location"))
+	}
+	return s.Get(ctx, id)
+}
+
+// Update повністю замінює витрату expense.ID; якщо RawDate порожня, береться день з Date, тож отриману
+// через Get витрату можна змінити й передати назад. Версія expense.Version має бути поточною,
+// інакше повертається ErrVersionConflict. Повертає збережену витрату з новою версією.
+func (s *ExpensesService) Update(ctx context.Context, expense models.Expense) (models.Expense, error) {
+	if expense.RawDate == "" && !expense.Date.IsZero() {
+		expense.RawDate = expense.Date.Format(validation.DateLayout)
+	}
+
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPut,
+		path:   expensePath(expense.ID),
+		header: ifMatch(expense.Version),
+		body:   expense,
+	}, nil)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	return s.Get(ctx, expense.ID)
+}
+
+// Patch змінює лише передані поля витрати (JSON Merge Patch: category, amount, rawdate, date).
+// Версія version має бути поточною. Повертає змінену витрату.
+func (s *ExpensesService) Patch(ctx context.Context, id, version int, fields map[string]any) (models.Expense, error) {
+	var expense models.Expense
+	_, err := s.client.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        expensePath(id),
+		header:      ifMatch(version),
+		body:        fields,
+		contentType: "application/merge-patch+json",
+	}, &expense)
+	return expense, err
+}
+
+// Delete переміщує витрату поточної версії version у кошик
+func (s *ExpensesService) Delete(ctx context.Context, id, version int) error {
+	_, err := s.client.do(ctx, request{method: http.MethodDelete, path: expensePath(id), header: ifMatch(version)}, nil)
+	return err
+}
+
+// Batch виконує кілька операцій за один запит. Помилки окремих операцій не є помилкою виклику:
+// їх описують результати, а BatchResponse.Committed показує, чи збережено зміни.
+func (s *ExpensesService) Batch(ctx context.Context, batch models.BatchRequest) (models.BatchResponse, error) {
+	var result models.BatchResponse
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/expenses/batch",
+		body:   batch,
+		accept: []int{http.StatusUnprocessableEntity}, // Атомарний пакет скасовано
+	}, &result)
+	return result, err
+}
+
+// Trash повертає видалені витрати
+func (s *ExpensesService) Trash(ctx context.Context) ([]models.Expense, error) {
+	var expenses []models.Expense
+	_, err := s.client.do(ctx, request{method: http.MethodGet, path: "/expenses/trash"}, &expenses)
+	return expenses, err
+}
+
+// Restore повертає витрату з кошика
+func (s *ExpensesService) Restore(ctx context.Context, id int) error {
+	_, err := s.client.do(ctx, request{method: http.MethodPost, path: "/expenses/trash/" + strconv.Itoa(id) + "/restore"}, nil)
+	return err
+}
+
+// Purge остаточно видаляє витрату з кошика
+func (s *ExpensesService) Purge(ctx context.Context, id int) error {
+	_, err := s.client.do(ctx, request{method: http.MethodDelete, path: "/expenses/trash/" + strconv.Itoa(id)}, nil)
+	return err
+}
+
+// History повертає ревізії витрати в хронологічному порядку
+func (s *ExpensesService) History(ctx context.Context, id int) ([]models.ExpenseRevision, error) {
+	var history []models.ExpenseRevision
+	_, err := s.client.do(ctx, request{method: http.MethodGet, path: expensePath(id) + "/history"}, &history)
+	return history, err
+}
+
+// Revert повертає витрату до стану після ревізії revision і повертає її
+func (s *ExpensesService) Revert(ctx context.Context, id, revision int) (models.Expense, error) {
+	var expense models.Expense
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   expensePath(id) + "/history/" + strconv.Itoa(revision) + "/revert",
+	}, &expense)
+	return expense, err
+}
+
+func expensePath(id int) string {
+	return "/expenses/" + strconv.Itoa(id)
+}
+
+// ifMatch повертає заголовок If-Match з ETag версії витрати (див. handlers.versionETag)
+func ifMatch(version int) http.Header {
+	return http.Header{"If-Match": {strconv.Quote(strconv.Itoa(version))}}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ChomuCake/uni-golang-labs/client"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+func runLogin(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "login")
+	username := flags.String("username", s.config.Username, "user name (default: the last logged in user)")
+	password := flags.String("password", "", "password (default: $"+envPassword+" or the first line of standard input)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return usageError(flags, "-username is required")
+	}
+
+	if *password == "" {
+		*password = s.env.getenv(envPassword)
+	}
+	if *password == "" {
+		fmt.Fprint(s.env.stderr, "Password: ")
+		line, err := bufio.NewReader(s.env.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("read password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	if err := s.client.Login(ctx, *username, *password); err != nil {
+		return err
+	}
+
+	s.config.Username = *username
+	s.config.Token = s.client.Token()
+	if err := s.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.env.stdout, "Logged in to %s as %s\n", s.config.Server, *username)
+	return nil
+}
+
+func runLogout(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "logout")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	s.config.Token = ""
+	if err := s.save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(s.env.stdout, "Logged out")
+	return nil
+}
+
+func runAdd(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "add")
+	category := flags.String("category", "", "category")
+	amount := flags.Int("amount", 0, "amount")
+	date := flags.String("date", "", "date in YYYY-MM-DD format (default: now)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *category == "" || *amount == 0 || flags.NArg() > 0 {
+		return usageError(flags, "-category and -amount are required")
+	}
+
+	expense := models.Expense{Category: *category, Amount: *amount, RawDate: *date}
+
+	// POST /expenses датує витрату поточним часом, тож витрата з датою додається пакетом з однієї операції
+	if *date == "" {
+		created, err := s.client.Expenses.Create(ctx, expense)
+		if err != nil {
+			return err
+		}
+		return writeExpenses(s.env.stdout, formatTable, []models.Expense{created})
+	}
+
+	imported, err := importBatch(ctx, s.client, []models.Expense{expense}, 0)
+	if err != nil {
+		return err
+	}
+	return writeExpenses(s.env.stdout, formatTable, imported)
+}
+
+// filterFlags додає прапорці фільтра переліку витрат
+func filterFlags(flags *flag.FlagSet) *client.ListFilter {
+	filter := &client.ListFilter{}
+	flags.StringVar(&filter.Sort, "sort", "", "day - today's expenses, month - this month's, all - all (default)")
+	flags.StringVar(&filter.Category, "category", "", "only expenses of this category")
+	flags.StringVar(&filter.From, "from", "", "only expenses dated on or after this day (YYYY-MM-DD)")
+	flags.StringVar(&filter.To, "to", "", "only expenses dated on or before this day (YYYY-MM-DD)")
+	return filter
+}
+
+func runList(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "list")
+	filter := filterFlags(flags)
+	limit := flags.Int("limit", 0, "show at most this many expenses (0 - all)")
+	output := outputFlag(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkFormat(flags, *output); err != nil {
+		return err
+	}
+	if *limit < 0 || flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments")
+	}
+	if *limit > 0 && *limit < client.DefaultPageSize {
+		filter.Limit = *limit
+	}
+
+	expenses := []models.Expense{}
+	for expense, err := range s.client.Expenses.All(ctx, *filter) {
+		if err != nil {
+			return err
+		}
+		expenses = append(expenses, expense)
+		if len(expenses) == *limit {
+			break
+		}
+	}
+	return writeExpenses(s.env.stdout, *output, expenses)
+}
+
+func runEdit(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "edit")
+	category := flags.String("category", "", "new category")
+	amount := flags.Int("amount", 0, "new amount")
+	date := flags.String("date", "", "new date in YYYY-MM-DD format")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "expected one expense ID")
+	}
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return usageError(flags, "%v", err)
+	}
+
+	// Змінюються лише задані прапорці
+	fields := map[string]any{}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "category":
+			fields["category"] = *category
+		case "amount":
+			fields["amount"] = *amount
+		case "date":
+			fields["rawdate"] = *date
+		}
+	})
+	if len(fields) == 0 {
+		return usageError(flags, "nothing to change; set -category, -amount or -date")
+	}
+
+	current, err := s.client.Expenses.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	edited, err := s.client.Expenses.Patch(ctx, id, current.Version, fields)
+	if err != nil {
+		return err
+	}
+	return writeExpenses(s.env.stdout, formatTable, []models.Expense{edited})
+}
+
+func runRemove(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "rm")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError(flags, "expected at least one expense ID")
+	}
+
+	ids := make([]int, flags.NArg())
+	for i, arg := range flags.Args() {
+		id, err := parseID(arg)
+		if err != nil {
+			return usageError(flags, "%v", err)
+		}
+		ids[i] = id
+	}
+
+	for _, id := range ids {
+		current, err := s.client.Expenses.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("expense %d: %w", id, err)
+		}
+		if err := s.client.Expenses.Delete(ctx, id, current.Version); err != nil {
+			return fmt.Errorf("expense %d: %w", id, err)
+		}
+		fmt.Fprintf(s.env.stdout, "Moved expense %d to the trash\n", id)
+	}
+	return nil
+}
+
+// summaryRow - сума витрат однієї групи
+type summaryRow struct {
+	Group string `json:"group"`
+	Count int    `json:"count"`
+	Total int    `json:"total"`
+}
+
+func runSummary(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "summary")
+	filter := filterFlags(flags)
+	by := flags.String("by", "category", "group by category or month")
+	output := outputFlag(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkFormat(flags, *output); err != nil {
+		return err
+	}
+	if *by != "category" && *by != "month" {
+		return usageError(flags, "-by must be category or month")
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments")
+	}
+
+	groups := map[string]*summaryRow{}
+	for expense, err := range s.client.Expenses.All(ctx, *filter) {
+		if err != nil {
+			return err
+		}
+
+		key := expense.Category
+		if *by == "month" {
+			key = expense.Date.Format("2006-01")
+		}
+		row, ok := groups[key]
+		if !ok {
+			row = &summaryRow{Group: key}
+			groups[key] = row
+		}
+		row.Count++
+		row.Total += expense.Amount
+	}
+
+	rows := make([]summaryRow, 0, len(groups))
+	for _, row := range groups {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Group < rows[j].Group })
+
+	return writeSummary(s.env.stdout, *output, rows)
+}
+
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, errors.New("expense ID must be a positive number, got " + strconv.Quote(arg))
+	}
+	return id, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ChomuCake/uni-golang-labs/client"
+)
+
+// defaultServer - адреса сервера, якщо її не задано ні прапорцем, ні в налаштуваннях, ні змінною середовища
+const defaultServer = "http://localhost:8080"
+
+// Змінні середовища expctl
+const (
+	envConfig   = "EXPCTL_CONFIG"
+	envServer   = "EXPCTL_SERVER"
+	envPassword = "EXPCTL_PASSWORD"
+)
+
+// fileConfig - вміст файлу налаштувань. Пароль не зберігається, тож після закінчення строку дії токена
+// потрібно знову виконати login.
+type fileConfig struct {
+	Server   string `yaml:"server"`
+	Username string `yaml:"username,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+}
+
+// session - налаштування та клієнт для виконання однієї команди
+type session struct {
+	env    env
+	path   string // Файл налаштувань
+	config fileConfig
+	client *client.Client
+}
+
+// newSession читає файл налаштувань і створює клієнт. Адреса сервера береться з прапорця -server,
+// файлу налаштувань, змінної EXPCTL_SERVER або defaultServer - саме в такому порядку.
+func newSession(configFile, server string, e env) (*session, error) {
+	path, err := configPath(configFile, e.getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{env: e, path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Ще не було входу
+	case err != nil:
+		return nil, fmt.Errorf("read config: %w", err)
+	default:
+		if err := yaml.Unmarshal(data, &s.config); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+
+	switch {
+	case server != "":
+		s.config.Server = server
+	case s.config.Server != "":
+	case e.getenv(envServer) != "":
+		s.config.Server = e.getenv(envServer)
+	default:
+		s.config.Server = defaultServer
+	}
+
+	s.client = client.New(s.config.Server)
+	s.client.UserAgent = "expctl"
+	s.client.SetToken(s.config.Token)
+	return s, nil
+}
+
+// configPath повертає шлях до файлу налаштувань: прапорець -config, змінна EXPCTL_CONFIG
+// або expctl/config.yaml у каталозі налаштувань користувача (напр. ~/.config)
+func configPath(configFile string, getenv func(string) string) (string, error) {
+	if configFile != "" {
+		return configFile, nil
+	}
+	if path := getenv(envConfig); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config: %w; set %s or use -config", err, envConfig)
+	}
+	return filepath.Join(dir, "expctl", "config.yaml"), nil
+}
+
+// save записує налаштування; файл містить токен, тож доступний лише власнику
+func (s *session) save() error {
+	data, err := yaml.Marshal(s.config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	return nil
+}
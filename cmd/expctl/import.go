@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ChomuCake/uni-golang-labs/client"
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// importBatchSize - кількість витрат в одному пакетному запиті (сервер приймає до 1000 операцій)
+const importBatchSize = 500
+
+// maxReportedErrors обмежує кількість помилок записів, що виводяться
+const maxReportedErrors = 20
+
+func runImport(ctx context.Context, s *session, args []string) error {
+	flags := newFlagSet(s, "import")
+	format := flags.String("format", "", "csv or json (default: by the file extension, csv for standard input)")
+	dryRun := flags.Bool("dry-run", false, "only check the file, do not add expenses")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "expected one file name or - for standard input")
+	}
+
+	name := flags.Arg(0)
+	if *format == "" {
+		*format = formatCSV
+		if strings.EqualFold(filepath.Ext(name), ".json") {
+			*format = formatJSON
+		}
+	}
+	if *format != formatCSV && *format != formatJSON {
+		return usageError(flags, "-format must be csv or json")
+	}
+
+	input := s.env.stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var expenses []models.Expense
+	var err error
+	if *format == formatJSON {
+		expenses, err = readJSONExpenses(input)
+	} else {
+		expenses, err = readCSVExpenses(input)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	// Файл перевіряється повністю до надсилання, щоб не імпортувати його частково через помилку в кінці
+	if err := validateExpenses(expenses); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if *dryRun {
+		fmt.Fprintf(s.env.stdout, "%d expenses are valid\n", len(expenses))
+		return nil
+	}
+
+	imported := 0
+	for start := 0; start < len(expenses); start += importBatchSize {
+		chunk := expenses[start:min(start+importBatchSize, len(expenses))]
+		if _, err := importBatch(ctx, s.client, chunk, start); err != nil {
+			return fmt.Errorf("imported %d of %d expenses: %w", imported, len(expenses), err)
+		}
+		imported += len(chunk)
+	}
+
+	fmt.Fprintf(s.env.stdout, "Imported %d expenses\n", imported)
+	return nil
+}
+
+// importBatch атомарно додає витрати одним пакетом і повертає збережені витрати.
+// offset - номер першої витрати у файлі для повідомлень про помилки.
+func importBatch(ctx context.Context, c *client.Client, expenses []models.Expense, offset int) ([]models.Expense, error) {
+	batch := models.BatchRequest{Mode: models.BatchModeAtomic}
+	for _, expense := range expenses {
+		batch.Operations = append(batch.Operations, models.BatchOperation{Op: models.BatchCreate, Expense: expense})
+	}
+
+	result, err := c.Expenses.Batch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []models.Expense
+	var errs []error
+	for _, item := range result.Results {
+		if item.Error != "" {
+			errs = append(errs, fmt.Errorf("record %d: %s", offset+item.Index+1, item.Error))
+		}
+		if item.Expense != nil {
+			created = append(created, *item.Expense)
+		}
+	}
+	if !result.Committed {
+		if len(errs) == 0 {
+			errs = append(errs, errors.New("the batch was not committed"))
+		}
+		return nil, errors.Join(errs...)
+	}
+	return created, nil
+}
+
+// readCSVExpenses читає витрати з CSV із рядком заголовка. Потрібні стовпці category та amount,
+// необов'язковий - date (YYYY-MM-DD); інші стовпці (напр. id і version з виводу list) ігноруються.
+func readCSVExpenses(r io.Reader) ([]models.Expense, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"category", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the header has no %q column", required)
+		}
+	}
+	dateColumn, hasDate := columns["date"]
+
+	var expenses []models.Expense
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return expenses, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		amount, err := strconv.Atoi(strings.TrimSpace(record[columns["amount"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: amount must be a number", line)
+		}
+
+		expense := models.Expense{Category: record[columns["category"]], Amount: amount}
+		if hasDate {
+			expense.RawDate = strings.TrimSpace(record[dateColumn])
+		}
+		expenses = append(expenses, expense)
+	}
+}
+
+// readJSONExpenses читає масив витрат у форматі API; дата береться з rawdate або, як у виводі list, з date
+func readJSONExpenses(r io.Reader) ([]models.Expense, error) {
+	var records []models.Expense
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+
+	expenses := make([]models.Expense, len(records))
+	for i, record := range records {
+		expenses[i] = models.Expense{Category: record.Category, Amount: record.Amount, RawDate: record.RawDate}
+		if expenses[i].RawDate == "" && !record.Date.IsZero() {
+			expenses[i].RawDate = record.Date.Format(validation.DateLayout)
+		}
+	}
+	return expenses, nil
+}
+
+// validateExpenses перевіряє витрати тими ж правилами, що й сервер
+func validateExpenses(expenses []models.Expense) error {
+	var errs []error
+	for i, expense := range expenses {
+		for _, fieldErr := range validation.Struct(expense) {
+			if len(errs) == maxReportedErrors {
+				return errors.Join(append(errs, errors.New("..."))...)
+			}
+			errs = append(errs, fmt.Errorf("record %d: %s %s", i+1, fieldErr.Field, fieldErr.Message))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+// Команда expctl - клієнт командного рядка REST API обліку витрат.
+//
+//	expctl [-config файл] [-server адреса] команда [прапорці] [аргументи]
+//
+// Команда login зберігає адресу сервера та токен у файлі налаштувань (див. configPath),
+// решта команд використовують збережений токен. Виконайте expctl help, щоб побачити перелік команд.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/ChomuCake/uni-golang-labs/client"
+)
+
+// errUsage - помилка в аргументах; її опис уже виведено разом із довідкою
+var errUsage = errors.New("usage error")
+
+// env - середовище виконання команди (підміняється в тестах)
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	getenv         func(string) string
+}
+
+// command - підкоманда expctl
+type command struct {
+	usage   string // Аргументи після назви команди
+	summary string
+	run     func(ctx context.Context, s *session, args []string) error
+}
+
+// commands заповнюється в init, бо команди самі звертаються до commands за довідкою
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"login":   {"-username NAME [-password PASSWORD]", "log in and save the token", runLogin},
+		"logout":  {"", "forget the saved token", runLogout},
+		"add":     {"-category CATEGORY -amount AMOUNT [-date YYYY-MM-DD]", "add an expense", runAdd},
+		"list":    {"[-sort day|month|all] [-category C] [-from DATE] [-to DATE] [-limit N] [-output table|json|csv]", "list expenses", runList},
+		"edit":    {"[-category C] [-amount N] [-date YYYY-MM-DD] ID", "change an expense", runEdit},
+		"rm":      {"ID...", "move expenses to the trash", runRemove},
+		"summary": {"[-by category|month] [-sort day|month|all] [-category C] [-from DATE] [-to DATE] [-output table|json|csv]", "total expenses by category or month", runSummary},
+		"import":  {"[-format csv|json] [-dry-run] FILE|-", "add expenses from a CSV or JSON file", runImport},
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	e := env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	if err := run(ctx, os.Args[1:], e); err != nil {
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "expctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run розбирає глобальні прапорці та виконує підкоманду
+func run(ctx context.Context, args []string, e env) error {
+	flags := flag.NewFlagSet("expctl", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	configFile := flags.String("config", "", "path to the config file (default: $EXPCTL_CONFIG or expctl/config.yaml in the user config directory)")
+	server := flags.String("server", "", "server address, e.g. http://localhost:8080 (default: saved by login, then $EXPCTL_SERVER)")
+	flags.Usage = func() { printUsage(e.stderr, flags) }
+
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	name := flags.Arg(0)
+	if name == "help" {
+		printUsage(e.stdout, flags)
+		return nil
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(e.stderr, "expctl: unknown command %q\n", name)
+		flags.Usage()
+		return errUsage
+	}
+
+	s, err := newSession(*configFile, *server, e)
+	if err != nil {
+		return err
+	}
+	err = cmd.run(ctx, s, flags.Args()[1:])
+	if errors.Is(err, client.ErrUnauthorized) && name != "login" {
+		return fmt.Errorf("%w\nlog in again with: expctl login -username NAME", err)
+	}
+	return err
+}
+
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: expctl [-config FILE] [-server URL] COMMAND [FLAGS] [ARGS]")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n           %s\n", name, commands[name].summary, strings.TrimSpace("expctl "+name+" "+commands[name].usage))
+	}
+
+	fmt.Fprintln(w, "\nGlobal flags:")
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+}
+
+// newFlagSet створює набір прапорців підкоманди з довідкою у форматі expctl
+func newFlagSet(s *session, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(s.env.stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(s.env.stderr, "Usage:", strings.TrimSpace("expctl "+name+" "+commands[name].usage))
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags розбирає прапорці підкоманди; помилку вже виведено flag
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// usageError виводить опис помилки та довідку підкоманди
+func usageError(flags *flag.FlagSet, format string, args ...any) error {
+	fmt.Fprintf(flags.Output(), "expctl %s: %s\n", flags.Name(), fmt.Sprintf(format, args...))
+	flags.Usage()
+	return errUsage
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/app"
+	"github.com/ChomuCake/uni-golang-labs/client"
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// testCLI - expctl із власним файлом налаштувань і сервером зі сховищем у пам'яті
+type testCLI struct {
+	t      *testing.T
+	config string
+	server string
+	stdin  string
+}
+
+func newTestCLI(t *testing.T) *testCLI {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "0123456789abcdef0123456789abcdef"
+	cfg.Database.Driver = database.DriverMemory
+	cfg.Auth.TokenTTL = time.Minute
+
+	application := app.New(cfg, database.NewMemoryStorage())
+	server := httptest.NewServer(application.Handler())
+	t.Cleanup(func() {
+		server.Close()
+		application.Close()
+	})
+
+	if err := client.New(server.URL).Register(t.Context(), "cli", "12345678"); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCLI{t: t, config: filepath.Join(t.TempDir(), "expctl", "config.yaml"), server: server.URL}
+}
+
+// run виконує команду й повертає її стандартний вивід
+func (c *testCLI) run(args ...string) (string, error) {
+	c.t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	e := env{
+		stdin:  strings.NewReader(c.stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(name string) string {
+			if name == envServer {
+				return c.server
+			}
+			return ""
+		},
+	}
+
+	err := run(c.t.Context(), append([]string{"-config", c.config}, args...), e)
+	return stdout.String(), err
+}
+
+// mustRun виконує команду, що має завершитися успішно
+func (c *testCLI) mustRun(args ...string) string {
+	c.t.Helper()
+
+	out, err := c.run(args...)
+	if err != nil {
+		c.t.Fatalf("expctl %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+func (c *testCLI) login() {
+	c.t.Helper()
+
+	c.stdin = "12345678\n"
+	c.mustRun("login", "-username", "cli")
+	c.stdin = ""
+}
+
+func TestLogin_SavesToken(t *testing.T) {
+	// Arrange
+	cli := newTestCLI(t)
+
+	// Act
+	cli.login()
+
+	// Assert
+	data, err := os.ReadFile(cli.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "server: "+cli.server) || !strings.Contains(string(data), "token: ") {
+		t.Errorf("Файл налаштувань не містить адреси сервера й токена:\n%s", data)
+	}
+	if info, err := os.Stat(cli.config); err == nil && info.Mode().Perm() != 0o600 {
+		t.Errorf("Отримано права файлу налаштувань %v, очікувалося 0600", info.Mode().Perm())
+	}
+
+	// Після виходу команди не мають доступу до API
+	cli.mustRun("logout")
+	if _, err := cli.run("list"); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Отримано помилку %v, очікувалася ErrUnauthorized", err)
+	}
+}
+
+func TestLogin_WrongPassword(t *testing.T) {
+	// Arrange
+	cli := newTestCLI(t)
+
+	// Act
+	_, err := cli.run("login", "-username", "cli", "-password", "87654321")
+
+	// Assert
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Отримано помилку %v, очікувалася ErrUnauthorized", err)
+	}
+	if _, statErr := os.Stat(cli.config); statErr == nil {
+		t.Error("Файл налаштувань збережено після невдалого входу")
+	}
+}
+
+func TestCommands(t *testing.T) {
+	// Arrange
+	cli := newTestCLI(t)
+	cli.login()
+
+	// Act & Assert: додавання
+	out := cli.mustRun("add", "-category", "food", "-amount", "120")
+	if !strings.Contains(out, "food") || !strings.Contains(out, "120") {
+		t.Errorf("Отримано некоректний вивід add:\n%s", out)
+	}
+	cli.mustRun("add", "-category", "rent", "-amount", "5000", "-date", "2024-05-01")
+	cli.mustRun("add", "-category", "food", "-amount", "80", "-date", "2024-05-20")
+
+	// Перелік з фільтром у форматі таблиці, JSON та CSV
+	out = cli.mustRun("list", "-sort", "all", "-from", "2024-05-01", "-to", "2024-05-31")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "2024-05-01") {
+		t.Errorf("Отримано некоректну таблицю:\n%s", out)
+	}
+
+	var expenses []models.Expense
+	if err := json.Unmarshal([]byte(cli.mustRun("list", "-category", "food", "-output", "json")), &expenses); err != nil {
+		t.Fatal(err)
+	}
+	if len(expenses) != 2 || expenses[0].Category != "food" {
+		t.Errorf("Отримано некоректний JSON: %+v", expenses)
+	}
+
+	out = cli.mustRun("list", "-sort", "all", "-limit", "1", "-output", "csv")
+	if out != "id,date,category,amount,version\n2,2024-05-01,rent,5000,1\n" {
+		t.Errorf("Отримано некоректний CSV:\n%s", out)
+	}
+
+	// Зміна та видалення
+	out = cli.mustRun("edit", "-amount", "90", "3")
+	if !strings.Contains(out, "90") || !strings.Contains(out, "2024-05-20") {
+		t.Errorf("Отримано некоректний вивід edit:\n%s", out)
+	}
+	if out := cli.mustRun("rm", "1"); out != "Moved expense 1 to the trash\n" {
+		t.Errorf("Отримано некоректний вивід rm: %q", out)
+	}
+	if _, err := cli.run("rm", "1"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Отримано помилку %v, очікувалася ErrNotFound", err)
+	}
+
+	// Підсумок
+	out = cli.mustRun("summary", "-output", "csv")
+	if out != "group,count,total\nfood,1,90\nrent,1,5000\n" {
+		t.Errorf("Отримано некоректний підсумок:\n%s", out)
+	}
+	out = cli.mustRun("summary", "-by", "month")
+	if !strings.Contains(out, "2024-05") || !strings.Contains(out, "TOTAL") || !strings.Contains(out, "5090") {
+		t.Errorf("Отримано некоректний підсумок за місяцями:\n%s", out)
+	}
+}
+
+func TestImport(t *testing.T) {
+	// Arrange
+	cli := newTestCLI(t)
+	cli.login()
+
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "expenses.csv")
+	err := os.WriteFile(csvFile, []byte("date,category,amount\n2024-04-01,rent,5000\n2024-04-02,food,120\n,taxi,40\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.json")
+	err = os.WriteFile(invalidFile, []byte(`[{"category": "food", "amount": 10}, {"category": "", "amount": 0, "rawdate": "01.04.2024"}]`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act & Assert: файл з помилками не імпортується навіть частково
+	_, err = cli.run("import", invalidFile)
+	if err == nil || !strings.Contains(err.Error(), "record 2: rawdate must be a date in YYYY-MM-DD format") ||
+		!strings.Contains(err.Error(), "record 2: category is required") {
+		t.Errorf("Отримано помилку %v, очікувалися помилки запису 2", err)
+	}
+
+	if out := cli.mustRun("import", "-dry-run", csvFile); out != "3 expenses are valid\n" {
+		t.Errorf("Отримано некоректний вивід перевірки: %q", out)
+	}
+	if out := cli.mustRun("list"); strings.Count(out, "\n") != 1 {
+		t.Errorf("Перевірка файлу додала витрати:\n%s", out)
+	}
+
+	if out := cli.mustRun("import", csvFile); out != "Imported 3 expenses\n" {
+		t.Errorf("Отримано некоректний вивід import: %q", out)
+	}
+
+	// Вивід list у форматі JSON можна імпортувати назад
+	cli.stdin = cli.mustRun("list", "-to", "2024-04-30", "-output", "json")
+	if out := cli.mustRun("import", "-format", "json", "-"); out != "Imported 2 expenses\n" {
+		t.Errorf("Отримано некоректний вивід import: %q", out)
+	}
+
+	out := cli.mustRun("summary", "-to", "2024-04-30", "-output", "csv")
+	if out != "group,count,total\nfood,2,240\nrent,2,10000\n" {
+		t.Errorf("Отримано некоректний підсумок після імпорту:\n%s", out)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	// Arrange
+	cli := newTestCLI(t)
+
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"add", "-category", "food"},
+		{"list", "-output", "xml"},
+		{"edit", "1"},
+		{"rm", "abc"},
+		{"summary", "-by", "year"},
+		{"import"},
+	}
+
+	for _, args := range tests {
+		// Act
+		_, err := cli.run(args...)
+
+		// Assert
+		if !errors.Is(err, errUsage) {
+			t.Errorf("expctl %s: отримано помилку %v, очікувалася помилка використання", strings.Join(args, " "), err)
+		}
+	}
+}
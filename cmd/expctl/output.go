@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// Формати виводу (прапорець -output)
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// expenseColumns - стовпці виводу витрат; CSV у цьому форматі приймає import
+var expenseColumns = []string{"id", "date", "category", "amount", "version"}
+
+func outputFlag(flags *flag.FlagSet) *string {
+	return flags.String("output", formatTable, "output format: table, json or csv")
+}
+
+func checkFormat(flags *flag.FlagSet, format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	}
+	return usageError(flags, "-output must be table, json or csv")
+}
+
+func writeExpenses(w io.Writer, format string, expenses []models.Expense) error {
+	if format == formatJSON {
+		return writeJSON(w, expenses)
+	}
+
+	rows := make([][]string, len(expenses))
+	for i, expense := range expenses {
+		rows[i] = []string{
+			strconv.Itoa(expense.ID),
+			expense.Date.Format(validation.DateLayout),
+			expense.Category,
+			strconv.Itoa(expense.Amount),
+			strconv.Itoa(expense.Version),
+		}
+	}
+	return writeRows(w, format, expenseColumns, rows, nil)
+}
+
+func writeSummary(w io.Writer, format string, rows []summaryRow) error {
+	count, total := 0, 0
+	for _, row := range rows {
+		count += row.Count
+		total += row.Total
+	}
+
+	if format == formatJSON {
+		return writeJSON(w, struct {
+			Groups []summaryRow `json:"groups"`
+			Count  int          `json:"count"`
+			Total  int          `json:"total"`
+		}{rows, count, total})
+	}
+
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = []string{row.Group, strconv.Itoa(row.Count), strconv.Itoa(row.Total)}
+	}
+	// Підсумковий рядок лише в таблиці: CSV призначений для обробки, де його легко порахувати
+	footer := []string{"TOTAL", strconv.Itoa(count), strconv.Itoa(total)}
+	return writeRows(w, format, []string{"group", "count", "total"}, cells, footer)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeRows виводить рядки таблицею з вирівняними стовпцями або у форматі CSV; footer - лише для таблиці
+func writeRows(w io.Writer, format string, header []string, rows [][]string, footer []string) error {
+	if format == formatCSV {
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return writer.Error()
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	printRow := func(cells []string) {
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+
+	printRow(strings.Split(strings.ToUpper(strings.Join(header, "\t")), "\t"))
+	for _, row := range rows {
+		printRow(row)
+	}
+	if footer != nil {
+		printRow(footer)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,363 @@
+// Package config описує налаштування сервера, бази даних та автентифікації.
+//
+// Налаштування беруться зі значень за замовчуванням, файлу YAML або TOML (формат визначається
+// розширенням) та змінних середовища - саме в такому порядку пріоритету (змінні середовища найвищий).
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DevJWTSecret - ключ підпису токенів для локальної розробки; застосовується лише в режимі розробки (Config.Dev)
+const DevJWTSecret = "fd9f5dc52a0b5728c5182c593e0fae7d821e6c7a0fe64b78e67450a0a6860d63"
+
+// minJWTSecretLength - мінімальна довжина ключа HS256 (256 біт)
+const minJWTSecretLength = 32
+
+// Config - усі налаштування застосунку
+type Config struct {
+	Dev       bool      `yaml:"dev" toml:"dev"` // Режим розробки: без auth.jwt_secret використовується DevJWTSecret
+	Server    Server    `yaml:"server" toml:"server"`
+	Database  Database  `yaml:"database" toml:"database"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	Retention Retention `yaml:"retention" toml:"retention"`
+	Log       Log       `yaml:"log" toml:"log"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+}
+
+// Server - налаштування HTTP-сервера
+type Server struct {
+	Addr            string        `yaml:"addr" toml:"addr"`                         // Адреса прослуховування, напр. ":8080"
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"` // Час на завершення запитів під час зупинки
+}
+
+// Database - налаштування сховища
+type Database struct {
+	Driver       string        `yaml:"driver" toml:"driver"`               // mysql, sqlite, postgres або memory
+	DSN          string        `yaml:"dsn" toml:"dsn"`                     // Порожній - типовий для СУБД
+	QueryTimeout time.Duration `yaml:"query_timeout" toml:"query_timeout"` // Тайм-аут однієї операції сховища (0 - без обмеження)
+	BatchTimeout time.Duration `yaml:"batch_timeout" toml:"batch_timeout"` // Тайм-аут пакетної операції (до тисячі змін в одній транзакції)
+
+	// Пул з'єднань (0 - без обмеження; для SQLite завжди одне відкрите з'єднання)
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+
+	// Перевірка з'єднання під час запуску: кількість спроб та пауза між ними
+	ConnectAttempts   int           `yaml:"connect_attempts" toml:"connect_attempts"`
+	ConnectRetryDelay time.Duration `yaml:"connect_retry_delay" toml:"connect_retry_delay"`
+}
+
+// Auth - налаштування JWT-токенів
+type Auth struct {
+	JWTSecret string        `yaml:"jwt_secret" toml:"jwt_secret"`
+	TokenTTL  time.Duration `yaml:"token_ttl" toml:"token_ttl"` // Напр. "24h"
+}
+
+// Retention - скільки зберігаються дані, що очищуються за розкладом
+type Retention struct {
+	Trash       time.Duration `yaml:"trash" toml:"trash"`             // Час у кошику до остаточного видалення, напр. "720h"
+	Idempotency time.Duration `yaml:"idempotency" toml:"idempotency"` // Час зберігання відповідей на запити з Idempotency-Key
+}
+
+// Log - налаштування журналу
+type Log struct {
+	Level string `yaml:"level" toml:"level"` // debug, info, warn або error
+}
+
+// Tracing - налаштування трасування OpenTelemetry
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`         // none, stdout або otlp
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`         // URL OTLP/HTTP колектора, напр. "http://localhost:4318"
+	ServiceName string  `yaml:"service_name" toml:"service_name"` // Атрибут service.name
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"` // Частка нових трас, що записуються (0..1)
+}
+
+// Змінні середовища, що перекривають значення з файлу
+const (
+	EnvFile              = "CONFIG_FILE"
+	EnvDev               = "DEV_MODE"
+	EnvAddr              = "SERVER_ADDR"
+	EnvShutdownTimeout   = "SHUTDOWN_TIMEOUT"
+	EnvDBDriver          = "DB_DRIVER"
+	EnvDBDSN             = "DB_DSN"
+	EnvDBTimeout         = "DB_QUERY_TIMEOUT"
+	EnvDBBatchTimeout    = "DB_BATCH_TIMEOUT"
+	EnvDBMaxOpenConns    = "DB_MAX_OPEN_CONNS"
+	EnvDBMaxIdleConns    = "DB_MAX_IDLE_CONNS"
+	EnvDBConnMaxLifetime = "DB_CONN_MAX_LIFETIME"
+	EnvDBConnectAttempts = "DB_CONNECT_ATTEMPTS"
+	EnvDBConnectDelay    = "DB_CONNECT_RETRY_DELAY"
+	EnvJWTSecret         = "JWT_SECRET"
+	EnvTokenTTL          = "TOKEN_TTL"
+	EnvTrashRetention    = "TRASH_RETENTION"
+	EnvIdempotencyTTL    = "IDEMPOTENCY_TTL"
+	EnvLogLevel          = "LOG_LEVEL"
+	EnvTracingExporter   = "TRACING_EXPORTER"
+	EnvTracingEndpoint   = "TRACING_ENDPOINT"
+	EnvTracingRatio      = "TRACING_SAMPLE_RATIO"
+)
+
+var (
+	drivers          = []string{"mysql", "sqlite", "postgres", "memory"}
+	tracingExporters = []string{"none", "stdout", "otlp"}
+)
+
+// Default повертає налаштування за замовчуванням; ключ підпису токенів не заданий і має бути налаштований
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:            ":8080",
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Database: Database{
+			Driver:            "mysql",
+			QueryTimeout:      5 * time.Second,
+			BatchTimeout:      30 * time.Second,
+			MaxOpenConns:      25,
+			MaxIdleConns:      25,
+			ConnMaxLifetime:   5 * time.Minute,
+			ConnectAttempts:   5,
+			ConnectRetryDelay: 2 * time.Second,
+		},
+		Auth: Auth{
+			TokenTTL: 24 * time.Hour,
+		},
+		Retention: Retention{
+			Trash:       30 * 24 * time.Hour,
+			Idempotency: 24 * time.Hour,
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "finance-tracker",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load читає налаштування з файлу path (якщо порожній - з файлу зі змінної CONFIG_FILE, якщо вона задана),
+// застосовує змінні середовища та перевіряє результат. У режимі розробки порожній ключ підпису
+// токенів замінюється на DevJWTSecret.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv(EnvFile)
+	}
+	if path != "" {
+		if err := readFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+	if cfg.Dev && cfg.Auth.JWTSecret == "" {
+		cfg.Auth.JWTSecret = DevJWTSecret
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func readFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("config: %s: unsupported format (use .yaml, .yml or .toml)", path)
+	}
+
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	envString(EnvAddr, &cfg.Server.Addr)
+	envString(EnvDBDriver, &cfg.Database.Driver)
+	envString(EnvDBDSN, &cfg.Database.DSN)
+	envString(EnvJWTSecret, &cfg.Auth.JWTSecret)
+	envString(EnvLogLevel, &cfg.Log.Level)
+	envString(EnvTracingExporter, &cfg.Tracing.Exporter)
+	envString(EnvTracingEndpoint, &cfg.Tracing.Endpoint)
+
+	return errors.Join(
+		envBool(EnvDev, &cfg.Dev),
+		envDuration(EnvShutdownTimeout, &cfg.Server.ShutdownTimeout),
+		envDuration(EnvDBTimeout, &cfg.Database.QueryTimeout),
+		envDuration(EnvDBBatchTimeout, &cfg.Database.BatchTimeout),
+		envInt(EnvDBMaxOpenConns, &cfg.Database.MaxOpenConns),
+		envInt(EnvDBMaxIdleConns, &cfg.Database.MaxIdleConns),
+		envDuration(EnvDBConnMaxLifetime, &cfg.Database.ConnMaxLifetime),
+		envInt(EnvDBConnectAttempts, &cfg.Database.ConnectAttempts),
+		envDuration(EnvDBConnectDelay, &cfg.Database.ConnectRetryDelay),
+		envDuration(EnvTokenTTL, &cfg.Auth.TokenTTL),
+		envDuration(EnvTrashRetention, &cfg.Retention.Trash),
+		envDuration(EnvIdempotencyTTL, &cfg.Retention.Idempotency),
+		envFloat(EnvTracingRatio, &cfg.Tracing.SampleRatio),
+	)
+}
+
+func envString(name string, dst *string) {
+	if v := os.Getenv(name); v != "" {
+		*dst = v
+	}
+}
+
+func envBool(name string, dst *bool) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", name, err)
+	}
+	*dst = b
+	return nil
+}
+
+func envDuration(name string, dst *time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", name, err)
+	}
+	*dst = d
+	return nil
+}
+
+func envInt(name string, dst *int) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
+
+func envFloat(name string, dst *float64) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", name, err)
+	}
+	*dst = f
+	return nil
+}
+
+// Validate перевіряє налаштування та повертає всі знайдені помилки разом
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr must not be empty"))
+	}
+	if c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must not be negative"))
+	}
+
+	if !slices.Contains(drivers, c.Database.Driver) {
+		errs = append(errs, fmt.Errorf("database.driver %q is not one of %s", c.Database.Driver, strings.Join(drivers, ", ")))
+	}
+
+	if c.Database.QueryTimeout < 0 || c.Database.BatchTimeout < 0 {
+		errs = append(errs, errors.New("database.query_timeout and batch_timeout must not be negative"))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 || c.Database.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("database.max_open_conns, max_idle_conns and conn_max_lifetime must not be negative"))
+	}
+	if c.Database.ConnectAttempts < 1 {
+		errs = append(errs, errors.New("database.connect_attempts must be at least 1"))
+	}
+	if c.Database.ConnectRetryDelay < 0 {
+		errs = append(errs, errors.New("database.connect_retry_delay must not be negative"))
+	}
+
+	switch {
+	case c.Auth.JWTSecret == "":
+		errs = append(errs, fmt.Errorf("auth.jwt_secret must be set (%s), or enable dev mode (%s) to use the development key", EnvJWTSecret, EnvDev))
+	case len(c.Auth.JWTSecret) < minJWTSecretLength:
+		errs = append(errs, fmt.Errorf("auth.jwt_secret must be at least %d bytes", minJWTSecretLength))
+	case c.Auth.JWTSecret == DevJWTSecret && !c.Dev:
+		errs = append(errs, errors.New("auth.jwt_secret must not be the development key outside dev mode"))
+	}
+	if c.Auth.TokenTTL <= 0 {
+		errs = append(errs, errors.New("auth.token_ttl must be positive"))
+	}
+
+	if c.Retention.Trash <= 0 || c.Retention.Idempotency <= 0 {
+		errs = append(errs, errors.New("retention.trash and retention.idempotency must be positive"))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("log.level %q is not one of debug, info, warn, error", c.Log.Level))
+	}
+
+	if !slices.Contains(tracingExporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("tracing.exporter %q is not one of %s", c.Tracing.Exporter, strings.Join(tracingExporters, ", ")))
+	}
+	if c.Tracing.Exporter == "otlp" && c.Tracing.Endpoint == "" {
+		errs = append(errs, errors.New("tracing.endpoint must be set for the otlp exporter"))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSecret - ключ підпису токенів достатньої довжини для тестів
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// clearEnv скидає змінні середовища, щоб вони не впливали на тест
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	for _, key := range []string{
+		EnvFile, EnvDev, EnvAddr, EnvShutdownTimeout, EnvDBDriver, EnvDBDSN, EnvDBTimeout,
+		EnvDBMaxOpenConns, EnvDBMaxIdleConns, EnvDBConnMaxLifetime, EnvDBConnectAttempts, EnvDBConnectDelay,
+		EnvJWTSecret, EnvTokenTTL, EnvTrashRetention, EnvIdempotencyTTL, EnvLogLevel, EnvTracingExporter, EnvTracingEndpoint, EnvTracingRatio,
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvJWTSecret, testSecret)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := Default()
+	want.Auth.JWTSecret = testSecret
+	if cfg != want {
+		t.Errorf("Отримано %+v, очікувалося %+v", cfg, want)
+	}
+}
+
+func TestLoad_DevModeUsesDevSecret(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvDev, "true")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if !cfg.Dev || cfg.Auth.JWTSecret != DevJWTSecret {
+		t.Errorf("Отримано режим %v та ключ %q, очікувався ключ розробки", cfg.Dev, cfg.Auth.JWTSecret)
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":9090"
+  shutdown_timeout: 30s
+database:
+  driver: sqlite
+  dsn: file:test.db
+  query_timeout: 3s
+  batch_timeout: 20s
+  max_open_conns: 10
+  max_idle_conns: 5
+  conn_max_lifetime: 1m
+  connect_attempts: 3
+  connect_retry_delay: 500ms
+auth:
+  jwt_secret: 0123456789abcdef0123456789abcdef
+  token_ttl: 2h
+retention:
+  trash: 168h
+  idempotency: 1h
+log:
+  level: debug
+tracing:
+  exporter: otlp
+  endpoint: http://collector:4318
+  service_name: expenses
+  sample_ratio: 0.5
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := Config{
+		Server: Server{Addr: ":9090", ShutdownTimeout: 30 * time.Second},
+		Database: Database{
+			Driver:            "sqlite",
+			DSN:               "file:test.db",
+			QueryTimeout:      3 * time.Second,
+			BatchTimeout:      20 * time.Second,
+			MaxOpenConns:      10,
+			MaxIdleConns:      5,
+			ConnMaxLifetime:   time.Minute,
+			ConnectAttempts:   3,
+			ConnectRetryDelay: 500 * time.Millisecond,
+		},
+		Auth:      Auth{JWTSecret: "0123456789abcdef0123456789abcdef", TokenTTL: 2 * time.Hour},
+		Retention: Retention{Trash: 7 * 24 * time.Hour, Idempotency: time.Hour},
+		Log:       Log{Level: "debug"},
+		Tracing:   Tracing{Exporter: "otlp", Endpoint: "http://collector:4318", ServiceName: "expenses", SampleRatio: 0.5},
+	}
+	if cfg != want {
+		t.Errorf("Отримано %+v, очікувалося %+v", cfg, want)
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[server]
+addr = ":9091"
+
+[database]
+driver = "postgres"
+
+[auth]
+jwt_secret = "0123456789abcdef0123456789abcdef"
+token_ttl = "30m"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if cfg.Server.Addr != ":9091" || cfg.Database.Driver != "postgres" || cfg.Auth.TokenTTL != 30*time.Minute {
+		t.Errorf("Отримано некоректні налаштування: %+v", cfg)
+	}
+	// Не задані у файлі значення лишаються типовими
+	if cfg.Database.QueryTimeout != Default().Database.QueryTimeout {
+		t.Errorf("Отримано тайм-аут %v, очікувався типовий", cfg.Database.QueryTimeout)
+	}
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := writeFile(t, "config.yml", "server:\n  addr: \":9090\"\ndatabase:\n  driver: sqlite\n")
+	t.Setenv(EnvJWTSecret, testSecret)
+	t.Setenv(EnvAddr, ":7070")
+	t.Setenv(EnvDBDriver, "memory")
+	t.Setenv(EnvTokenTTL, "1h")
+	t.Setenv(EnvShutdownTimeout, "5s")
+	t.Setenv(EnvDBMaxOpenConns, "7")
+	t.Setenv(EnvDBConnectAttempts, "2")
+	t.Setenv(EnvTrashRetention, "48h")
+	t.Setenv(EnvIdempotencyTTL, "10m")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if cfg.Server.Addr != ":7070" || cfg.Database.Driver != "memory" || cfg.Auth.TokenTTL != time.Hour {
+		t.Errorf("Змінні середовища не застосовано: %+v", cfg)
+	}
+	if cfg.Server.ShutdownTimeout != 5*time.Second || cfg.Database.MaxOpenConns != 7 || cfg.Database.ConnectAttempts != 2 {
+		t.Errorf("Змінні середовища не застосовано: %+v", cfg)
+	}
+	if cfg.Retention != (Retention{Trash: 48 * time.Hour, Idempotency: 10 * time.Minute}) {
+		t.Errorf("Змінні середовища не застосовано: %+v", cfg.Retention)
+	}
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	t.Setenv(EnvFile, writeFile(t, "config.yaml", "server:\n  addr: \":6060\"\nauth:\n  jwt_secret: "+testSecret+"\n"))
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if cfg.Server.Addr != ":6060" {
+		t.Errorf("Отримано адресу %q, очікувалася %q", cfg.Server.Addr, ":6060")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		body string
+		env  map[string]string
+		want string
+	}{
+		{name: "unknown key", file: "c.yaml", body: "server:\n  port: 80\n", want: "port"},
+		{name: "unknown toml key", file: "c.toml", body: "[server]\nport = 80\n", want: "server.port"},
+		{name: "unsupported format", file: "c.json", body: "{}", want: "unsupported format"},
+		{name: "bad driver", file: "c.yaml", body: "database:\n  driver: oracle\n", want: "database.driver"},
+		{name: "short secret", file: "c.yaml", body: "auth:\n  jwt_secret: short\n", env: map[string]string{EnvJWTSecret: ""}, want: "auth.jwt_secret"},
+		{name: "no secret", file: "c.yaml", body: "", env: map[string]string{EnvJWTSecret: ""}, want: "auth.jwt_secret must be set"},
+		{name: "dev secret outside dev mode", file: "c.yaml", body: "", env: map[string]string{EnvJWTSecret: DevJWTSecret}, want: "development key"},
+		{name: "bad dev mode env", file: "c.yaml", body: "", env: map[string]string{EnvDev: "maybe"}, want: EnvDev},
+		{name: "bad ttl env", file: "c.yaml", body: "", env: map[string]string{EnvTokenTTL: "soon"}, want: EnvTokenTTL},
+		{name: "bad timeout env", file: "c.yaml", body: "", env: map[string]string{EnvDBTimeout: "fast"}, want: EnvDBTimeout},
+		{name: "negative timeout", file: "c.yaml", body: "database:\n  query_timeout: -1s\n", want: "database.query_timeout"},
+		{name: "bad pool env", file: "c.yaml", body: "", env: map[string]string{EnvDBMaxOpenConns: "many"}, want: EnvDBMaxOpenConns},
+		{name: "negative pool size", file: "c.yaml", body: "database:\n  max_idle_conns: -1\n", want: "max_idle_conns"},
+		{name: "no connect attempts", file: "c.yaml", body: "database:\n  connect_attempts: 0\n", want: "database.connect_attempts"},
+		{name: "negative shutdown timeout", file: "c.yaml", body: "server:\n  shutdown_timeout: -1s\n", want: "server.shutdown_timeout"},
+		{name: "bad log level", file: "c.yaml", body: "", env: map[string]string{EnvLogLevel: "loud"}, want: "log.level"},
+		{name: "bad tracing exporter", file: "c.yaml", body: "tracing:\n  exporter: jaeger\n", want: "tracing.exporter"},
+		{name: "bad sample ratio env", file: "c.yaml", body: "", env: map[string]string{EnvTracingRatio: "half"}, want: EnvTracingRatio},
+		{name: "sample ratio out of range", file: "c.yaml", body: "tracing:\n  sample_ratio: 2\n", want: "tracing.sample_ratio"},
+		{name: "negative ttl", file: "c.yaml", body: "auth:\n  token_ttl: -1h\n", want: "auth.token_ttl"},
+		{name: "zero trash retention", file: "c.yaml", body: "retention:\n  trash: 0s\n", want: "retention.trash"},
+		{name: "bad idempotency ttl env", file: "c.yaml", body: "", env: map[string]string{EnvIdempotencyTTL: "day"}, want: EnvIdempotencyTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.file, tt.body)
+			t.Setenv(EnvJWTSecret, testSecret)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Отримано помилку %v, очікувалася помилка з %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	cfg := Config{}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Очікувалася помилка")
+	}
+
+	for _, field := range []string{"server.addr", "database.driver", "auth.jwt_secret", "auth.token_ttl"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Помилка %q не містить %s", err, field)
+		}
+	}
+}
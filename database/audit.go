@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// Audit - хто та в межах якого запиту змінює витрату. Методи ExpenseDB, що змінюють витрати, записують
+// ревізію історії в тій самій транзакції, що й саму зміну: зміна не зберігається без запису в історії,
+// а помилка запису історії скасовує зміну.
+type Audit struct {
+	ActorID   int
+	RequestID string
+	Action    string // Замінює типову для методу дію ревізії (напр. models.ActionRevert для UpdateUserExpenses)
+}
+
+// trashRetentionRequestID - RequestID ревізій, які записує автоматичне очищення кошика (PurgeTrash)
+const trashRetentionRequestID = "purge-trash"
+
+// trashRetentionAudit - від імені власника витрати, бо очищення кошика виконує планувальник, а не користувач
+func trashRetentionAudit(ownerID int) Audit {
+	return Audit{ActorID: ownerID, RequestID: trashRetentionRequestID}
+}
+
+// batchActions - дії ревізій для операцій пакета
+var batchActions = map[string]string{
+	models.BatchCreate: models.ActionCreate,
+	models.BatchUpdate: models.ActionUpdate,
+	models.BatchDelete: models.ActionDelete,
+}
+
+// revisionInsertFunc записує ревізію історії запитом конкретної СУБД
+type revisionInsertFunc func(ctx context.Context, q execer, revision models.ExpenseRevision) error
+
+// revision формує незмінний запис про зміну витрати.
+// before дорівнює nil при створенні, after - при остаточному видаленні.
+func (a Audit) revision(action string, before, after *models.Expense) (models.ExpenseRevision, error) {
+	if a.Action != "" {
+		action = a.Action
+	}
+
+	revision := models.ExpenseRevision{
+		ActorID:   a.ActorID,
+		Action:    action,
+		RequestID: a.RequestID,
+		CreatedAt: utcNow(),
+	}
+
+	for _, state := range []struct {
+		expense *models.Expense
+		dst     *json.RawMessage
+	}{{before, &revision.Before}, {after, &revision.After}} {
+		if state.expense == nil {
+			continue
+		}
+
+		revision.ExpenseID = state.expense.ID
+		revision.UserID = state.expense.UserID
+
+		snapshot := *state.expense
+		snapshot.RawDate = ""
+
+		raw, err := json.Marshal(snapshot)
+		if err != nil {
+			return models.ExpenseRevision{}, err
+		}
+		*state.dst = raw
+	}
+
+	return revision, nil
+}
+
+// record записує ревізію зміни в транзакції tx
+func (a Audit) record(ctx context.Context, tx *sql.Tx, insert revisionInsertFunc, action string, before, after *models.Expense) error {
+	revision, err := a.revision(action, before, after)
+	if err != nil {
+		return err
+	}
+
+	return insert(ctx, tx, revision)
+}
+
+// inTx виконує fn в транзакції та фіксує її, якщо fn завершилась без помилки
+func inTx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // після Commit нічого не робить
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addAudited зберігає витрату функцією create та записує ревізію її створення в тій самій транзакції
+func addAudited(ctx context.Context, conn *sql.DB, expense models.Expense, audit Audit, create batchCreateFunc, insert revisionInsertFunc) (int, error) {
+	results := make([]BatchResult, 1)
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		ops := []models.BatchOperation{{Op: models.BatchCreate, Expense: expense}}
+		if err := create(ctx, tx, expense.UserID, ops, results); err != nil {
+			return err
+		}
+
+		return audit.record(ctx, tx, insert, models.ActionCreate, nil, results[0].After)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return results[0].After.ID, nil
+}
+
+// applyAudited виконує зміну витрати функцією apply та записує ревізію action в тій самій транзакції
+func applyAudited(ctx context.Context, conn *sql.DB, userID int, op models.BatchOperation, action string, audit Audit, apply batchApplyFunc, insert revisionInsertFunc) error {
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		var result BatchResult
+		if err := apply(ctx, tx, userID, op, &result); err != nil {
+			return err
+		}
+
+		return audit.record(ctx, tx, insert, action, result.Before, result.After)
+	})
+}
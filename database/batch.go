@@ -0,0 +1,17 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// ErrBatchAborted повертається для операцій, скасованих через помилку іншої операції атомарного пакета
+var ErrBatchAborted = errors.New("batch aborted")
+
+// BatchResult - результат однієї операції пакета: стан витрати до та після зміни або помилка
+type BatchResult struct {
+	Before *models.Expense
+	After  *models.Expense
+	Err    error
+}
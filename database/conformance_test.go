@@ -0,0 +1,38 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/database/dbtest"
+)
+
+// newSQLStores створює сховища тестової СУБД (див. dbtest.Driver) над новою базою даних
+func newSQLStores(t *testing.T) dbtest.Stores {
+	storage := dbtest.NewStorage(t)
+	return dbtest.Stores{Expenses: storage.Expenses, Users: storage.Users, History: storage.History, Idempotency: storage.Idempotency}
+}
+
+func newMemoryStores(t *testing.T) dbtest.Stores {
+	memoryDB := database.NewMemoryDB()
+	return dbtest.Stores{
+		Expenses:    &database.MemoryExpenseDB{DB: memoryDB},
+		Users:       &database.MemoryUserDB{DB: memoryDB},
+		History:     &database.MemoryExpenseHistoryDB{DB: memoryDB},
+		Idempotency: &database.MemoryIdempotencyDB{DB: memoryDB},
+	}
+}
+
+func TestSQLConformance(t *testing.T) {
+	dbtest.RunExpenseDBSuite(t, newSQLStores)
+	dbtest.RunUserDBSuite(t, newSQLStores)
+	dbtest.RunExpenseHistoryDBSuite(t, newSQLStores)
+	dbtest.RunIdempotencyDBSuite(t, newSQLStores)
+}
+
+func TestMemoryConformance(t *testing.T) {
+	dbtest.RunExpenseDBSuite(t, newMemoryStores)
+	dbtest.RunUserDBSuite(t, newMemoryStores)
+	dbtest.RunExpenseHistoryDBSuite(t, newMemoryStores)
+	dbtest.RunIdempotencyDBSuite(t, newMemoryStores)
+}
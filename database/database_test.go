@@ -0,0 +1,28 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestMySQLDSN_EnablesParseTime(t *testing.T) {
+	dsn, err := mysqlDSN("user:pass@tcp(db:3306)/expenses?charset=utf8mb4")
+	if err != nil {
+		t.Fatalf("mysqlDSN returned error: %v", err)
+	}
+
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("ParseDSN returned error: %v", err)
+	}
+	if !cfg.ParseTime || cfg.DBName != "expenses" || cfg.Addr != "db:3306" || cfg.Params["charset"] != "utf8mb4" {
+		t.Errorf("Отримано налаштування %+v, очікувалося parseTime=true зі збереженням решти DSN", cfg)
+	}
+}
+
+func TestMySQLDSN_RejectsMalformed(t *testing.T) {
+	if _, err := mysqlDSN("user:pass@tcp(db:3306"); err == nil {
+		t.Error("Некоректний DSN прийнято без помилки")
+	}
+}
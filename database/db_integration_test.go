@@ -1,103 +1,38 @@
 //nolint
 //------------------------Інтеграційний тест------------------------------
 
-package database
+package database_test
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
-	"strconv"
 	"testing"
 	"time"
 
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/database/dbtest"
 	"github.com/ChomuCake/uni-golang-labs/models"
 )
 
-const testDBName = "test_db"
-
-func InitTestDB() (*sql.DB, error) {
-	// Формування рядка підключення до тестової бази даних
-	dsn := "root:12345@tcp(localhost:3306)/" + testDBName + "?parseTime=true"
-
-	// Встановлення з'єднання з тестовою базою даних
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to test database: %v", err)
-	}
-
-	// Очищення тестової бази даних перед початком тестів
-	if err := СlearTestDB(db); err != nil {
-		return nil, fmt.Errorf("failed to clear test database: %v", err)
-	}
+func TestGetUserExpensesIntegration(t *testing.T) {
+	// Підготовка тестової бази даних
+	storage := dbtest.NewStorage(t)
 
-	return db, nil
+	testStorageIntegration(t, storage.Expenses, storage.Users, storage.History, storage.Idempotency)
 }
 
-func СlearTestDB(db *sql.DB) error {
-	// Видалення і створення бази даних
-	_, err := db.Exec("DROP DATABASE IF EXISTS " + testDBName)
-	if err != nil {
-		return fmt.Errorf("failed to drop test database: %v", err)
-	}
-
-	_, err = db.Exec("CREATE DATABASE " + testDBName)
-	if err != nil {
-		return fmt.Errorf("failed to create test database: %v", err)
-	}
-
-	_, err = db.Exec("USE " + testDBName)
-	if err != nil {
-		return fmt.Errorf("failed to switch to test database: %v", err)
-	}
-
-	// Створення таблиці `users`
-	_, err = db.Exec(`
-		CREATE TABLE users (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			username VARCHAR(255) NOT NULL,
-			password VARCHAR(255) NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create users table: %v", err)
-	}
-
-	// Створення таблиці `expenses`
-	_, err = db.Exec(`
-		CREATE TABLE expenses (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			date DATE NOT NULL,
-			category VARCHAR(255) NOT NULL,
-			amount INT NOT NULL,
-			user_id INT NOT NULL,
-			FOREIGN KEY (user_id) REFERENCES users(id)
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create expenses table: %v", err)
-	}
+func TestMemoryDBIntegration(t *testing.T) {
+	memoryDB := database.NewMemoryDB()
 
-	return nil
+	testStorageIntegration(t, &database.MemoryExpenseDB{DB: memoryDB}, &database.MemoryUserDB{DB: memoryDB},
+		&database.MemoryExpenseHistoryDB{DB: memoryDB}, &database.MemoryIdempotencyDB{DB: memoryDB})
 }
 
-func TestGetUserExpensesIntegration(t *testing.T) {
-	// Підготовка тестової бази даних
-	testDB, err := InitTestDB()
-	if err != nil {
-		t.Fatalf("Failed to initialize test database: %v", err)
-	}
-	defer testDB.Close()
-
-	// Створення тестового об'єкта бази даних
-	expenseDB := MySQLExpenseDB{
-		DB: testDB,
-	}
-
-	userDB := MySQLUserDB{
-		DB: testDB,
-	}
+// testStorageIntegration перевіряє сховища на порожній базі даних
+func testStorageIntegration(t *testing.T, expenseDB database.ExpenseDB, userDB database.UserDB, historyDB database.ExpenseHistoryDB, idempotencyDB database.IdempotencyDB) {
+	var err error
 
 	newUser := models.User{
 		Username: "TestName",
@@ -110,6 +45,9 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 		ID:       1,
 	}
 
+	// Зміни витрат записуються в історію від імені власника
+	audit := database.Audit{ActorID: expectedUser.ID, RequestID: "integration"}
+
 	// Створення об'єкту моделі витрат
 	newExpense := models.Expense{
 		ID:       1,
@@ -117,6 +55,7 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 		Category: "TestExpenses",
 		Amount:   100,
 		UserID:   expectedUser.ID,
+		Version:  1,
 	}
 
 	// GetUserExpenses повинен усе крім юзерАЙді(бо нема сенсу)
@@ -125,6 +64,7 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 		Date:     time.Now().Truncate(24 * time.Hour).UTC(),
 		Category: "TestExpenses",
 		Amount:   100,
+		Version:  1,
 	}
 
 	ExpensesUpdate := models.Expense{
@@ -132,19 +72,22 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 		Date:     newExpense.Date,
 		Category: "Updated " + newExpense.Category,
 		Amount:   999 + newExpense.Amount,
+		Version:  newExpense.Version,
 	}
 
 	// Тестування створення і отримання користувача
 	// Результат після створення користувача він має отримуватись з бд
 	t.Run("create and get User", func(t *testing.T) {
-		err = userDB.AddUser(newUser)
+		ctx := t.Context()
+
+		err = userDB.AddUser(ctx, newUser)
 
 		if err != nil {
 			t.Errorf("failed to add user with error: %v", err)
 		}
 
 		fmt.Println(newUser)
-		user, err := userDB.GetUserByID(expectedUser.ID)
+		user, err := userDB.GetUserByID(ctx, expectedUser.ID)
 		if err != nil {
 			t.Errorf("failed to get user with error: %v", err)
 		}
@@ -157,14 +100,20 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 	// Тестування створення і отримання витрат користувача
 	// Результат користувач повинен отримувати нову витрату після створення її у бд
 	t.Run("create and get UserExpneses", func(t *testing.T) {
-		err = expenseDB.AddExpense(newExpense)
+		ctx := t.Context()
+
+		id, err := expenseDB.AddExpense(ctx, newExpense, audit)
 
 		if err != nil {
 			t.Errorf("failed to add expense with error: %v", err)
 		}
 
+		if id != newExpense.ID {
+			t.Errorf("inserted expense id is wrong; actual: %v, expected: %v", id, newExpense.ID)
+		}
+
 		fmt.Println(newExpense)
-		expense, err := expenseDB.GetUserExpenses(expectedUser.ID)
+		expense, err := expenseDB.GetUserExpenses(ctx, expectedUser.ID)
 		if err != nil {
 			t.Errorf("failed to get user expneses with error: %v", err)
 		}
@@ -174,17 +123,46 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 		}
 	})
 
+	// Тестування отримання однієї витрати
+	// Результат витрата повертається лише власнику
+	t.Run("get UserExpense by id", func(t *testing.T) {
+		ctx := t.Context()
+
+		expense, err := expenseDB.GetExpenseByID(ctx, expectedUser.ID, newExpense.ID)
+		if err != nil {
+			t.Errorf("failed to get expense with error: %v", err)
+		}
+
+		if !reflect.DeepEqual(newExpense, expense) {
+			t.Errorf("expense data is corrupted; actual: %v, expected: %v", expense, newExpense)
+		}
+
+		_, err = expenseDB.GetExpenseByID(ctx, expectedUser.ID+1, newExpense.ID)
+		if !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("foreign expense is visible; actual error: %v, expected: %v", err, database.ErrNotFound)
+		}
+	})
+
 	// Тестування оновлення і отримання витрат користувача
 	// Результат користувач повинен отримувати оновлені витрати після оновлення їх у бд
 	t.Run("update and get UserExpnese", func(t *testing.T) {
-		err = expenseDB.UpdateUserExpenses(ExpensesUpdate)
+		ctx := t.Context()
+
+		err = expenseDB.UpdateUserExpenses(ctx, expectedUser.ID, ExpensesUpdate, audit)
 
 		if err != nil {
 			t.Errorf("failed update expense with error: %v", err)
 		}
 
+		// Після оновлення версія збільшується, тож повторне оновлення зі старою версією відхиляється
+		err = expenseDB.UpdateUserExpenses(ctx, expectedUser.ID, ExpensesUpdate, audit)
+		if !errors.Is(err, database.ErrVersionConflict) {
+			t.Errorf("stale update is accepted; actual error: %v, expected: %v", err, database.ErrVersionConflict)
+		}
+		ExpensesUpdate.Version++
+
 		fmt.Println(ExpensesUpdate)
-		expense, err := expenseDB.GetUserExpenses(expectedUser.ID)
+		expense, err := expenseDB.GetUserExpenses(ctx, expectedUser.ID)
 		if err != nil {
 			t.Errorf("failed to get user expneses with error: %v", err)
 		}
@@ -197,13 +175,15 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 	// Тестування видалення і отримання витрат користувача
 	// Результат користувач повинен отримувати 0 витрат після видалення їх з бд
 	t.Run("delete and get UserExpnese", func(t *testing.T) {
-		err = expenseDB.DeleteExpense(strconv.Itoa(ExpensesUpdate.ID))
+		ctx := t.Context()
+
+		err = expenseDB.DeleteExpense(ctx, expectedUser.ID, ExpensesUpdate.ID, ExpensesUpdate.Version, audit)
 
 		if err != nil {
 			t.Errorf("failed to delete expense with error: %v", err)
 		}
 
-		expense, err := expenseDB.GetUserExpenses(expectedUser.ID)
+		expense, err := expenseDB.GetUserExpenses(ctx, expectedUser.ID)
 		if err != nil {
 			t.Errorf("failed to get user expneses with error: %v", err)
 		}
@@ -213,15 +193,215 @@ func TestGetUserExpensesIntegration(t *testing.T) {
 		}
 	})
 
+	// Тестування кошика: видалена витрата потрапляє в кошик, відновлюється та остаточно видаляється
+	// Результат витрата повертається до списку після відновлення і зникає з кошика після очищення
+	t.Run("trash restore and purge UserExpense", func(t *testing.T) {
+		ctx := t.Context()
+
+		trash, err := expenseDB.GetUserTrash(ctx, expectedUser.ID)
+		if err != nil {
+			t.Errorf("failed to get user trash with error: %v", err)
+		}
+
+		if len(trash) != 1 || trash[0].ID != ExpensesUpdate.ID || trash[0].DeletedAt == nil {
+			t.Errorf("trash data is corrupted; actual: %v", trash)
+		}
+
+		err = expenseDB.RestoreExpense(ctx, expectedUser.ID, ExpensesUpdate.ID, audit)
+		if err != nil {
+			t.Errorf("failed to restore expense with error: %v", err)
+		}
+
+		expense, err := expenseDB.GetUserExpenses(ctx, expectedUser.ID)
+		if err != nil {
+			t.Errorf("failed to get user expneses with error: %v", err)
+		}
+
+		if len(expense) != 1 {
+			t.Errorf("restored expense is missing; actual: %v", expense)
+		}
+
+		// Видалення (+1) і відновлення (+1) збільшують версію
+		err = expenseDB.DeleteExpense(ctx, expectedUser.ID, ExpensesUpdate.ID, ExpensesUpdate.Version+2, audit)
+		if err != nil {
+			t.Errorf("failed to delete expense with error: %v", err)
+		}
+
+		purged, err := expenseDB.PurgeTrash(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Errorf("failed to purge trash with error: %v", err)
+		}
+
+		if purged != 1 {
+			t.Errorf("purged count is wrong; actual: %v, expected: %v", purged, 1)
+		}
+
+		err = expenseDB.RestoreExpense(ctx, expectedUser.ID, ExpensesUpdate.ID, audit)
+		if !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("purged expense can be restored; actual error: %v, expected: %v", err, database.ErrNotFound)
+		}
+	})
+
+	// Тестування пакетних операцій
+	// Результат атомарний пакет з помилкою нічого не змінює, частковий - застосовує успішні операції
+	t.Run("bulk insert and batch UserExpenses", func(t *testing.T) {
+		ctx := t.Context()
+
+		bulk := []models.BatchOperation{
+			{Op: models.BatchCreate, Expense: models.Expense{Date: newExpense.Date, Category: "Bulk1", Amount: 1}},
+			{Op: models.BatchCreate, Expense: models.Expense{Date: newExpense.Date, Category: "Bulk2", Amount: 2}},
+			{Op: models.BatchCreate, Expense: models.Expense{Date: newExpense.Date, Category: "Bulk3", Amount: 3}},
+		}
+
+		created, err := expenseDB.ApplyBatch(ctx, expectedUser.ID, bulk, true, audit)
+		if err != nil {
+			t.Fatalf("failed to bulk insert expenses with error: %v", err)
+		}
+
+		ids := make([]int, len(created))
+		for i, result := range created {
+			if result.Err != nil {
+				t.Fatalf("bulk insert failed; actual: %+v", result)
+			}
+			ids[i] = result.After.ID
+
+			expense, err := expenseDB.GetExpenseByID(ctx, expectedUser.ID, ids[i])
+			if err != nil || expense.Category != bulk[i].Expense.Category {
+				t.Errorf("bulk inserted expense is corrupted; actual: %v, error: %v", expense, err)
+			}
+		}
+
+		ops := []models.BatchOperation{
+			{Op: models.BatchUpdate, ID: ids[0], Version: 1, Expense: models.Expense{Date: newExpense.Date, Category: "Batch", Amount: 10}},
+			{Op: models.BatchDelete, ID: ids[2] + 1000, Version: 1},
+		}
+
+		results, err := expenseDB.ApplyBatch(ctx, expectedUser.ID, ops, true, audit)
+		if err != nil {
+			t.Errorf("failed to apply atomic batch with error: %v", err)
+		}
+
+		if !errors.Is(results[0].Err, database.ErrBatchAborted) || !errors.Is(results[1].Err, database.ErrNotFound) {
+			t.Errorf("atomic batch results are wrong; actual: %+v", results)
+		}
+
+		expense, _ := expenseDB.GetExpenseByID(ctx, expectedUser.ID, ids[0])
+		if expense.Category != "Bulk1" || expense.Version != 1 {
+			t.Errorf("aborted batch changed data; actual: %v", expense)
+		}
+
+		ops = append(ops, models.BatchOperation{Op: models.BatchCreate, Expense: models.Expense{Date: newExpense.Date, Category: "Batch", Amount: 5}})
+		results, err = expenseDB.ApplyBatch(ctx, expectedUser.ID, ops, false, audit)
+		if err != nil {
+			t.Errorf("failed to apply partial batch with error: %v", err)
+		}
+
+		if results[0].Err != nil || results[0].After.Version != 2 || !errors.Is(results[1].Err, database.ErrNotFound) ||
+			results[2].Err != nil || results[2].After.ID == 0 {
+			t.Errorf("partial batch results are wrong; actual: %+v", results)
+		}
+
+		expense, _ = expenseDB.GetExpenseByID(ctx, expectedUser.ID, ids[0])
+		if expense.Category != "Batch" || expense.Version != 2 {
+			t.Errorf("partial batch did not apply update; actual: %v", expense)
+		}
+	})
+
+	// Тестування історії змін витрати
+	// Результат зміни попередніх тестів записані в історію в порядку виконання, ревізії видно лише власнику
+	t.Run("get ExpenseHistory", func(t *testing.T) {
+		ctx := t.Context()
+
+		history, err := historyDB.GetExpenseHistory(ctx, expectedUser.ID, newExpense.ID)
+		if err != nil {
+			t.Errorf("failed to get history with error: %v", err)
+		}
+
+		var actions []string
+		for _, revision := range history {
+			actions = append(actions, revision.Action)
+		}
+		expectedActions := []string{models.ActionCreate, models.ActionUpdate, models.ActionDelete, models.ActionRestore, models.ActionDelete, models.ActionPurge}
+		if !reflect.DeepEqual(actions, expectedActions) || history[0].Before != nil || history[0].RequestID != "integration" {
+			t.Errorf("history data is corrupted; actual: %+v", history)
+		}
+
+		stored, err := historyDB.GetRevision(ctx, expectedUser.ID, newExpense.ID, history[1].ID)
+		if err != nil {
+			t.Errorf("failed to get revision with error: %v", err)
+		}
+
+		if stored.Action != models.ActionUpdate {
+			t.Errorf("revision data is corrupted; actual: %+v", stored)
+		}
+
+		_, err = historyDB.GetRevision(ctx, expectedUser.ID+1, newExpense.ID, history[1].ID)
+		if !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("foreign revision is visible; actual error: %v, expected: %v", err, database.ErrNotFound)
+		}
+	})
+
+	t.Run("reserve, complete and release IdempotencyKey", func(t *testing.T) {
+		ctx := t.Context()
+
+		now := time.Now().Truncate(time.Second).UTC()
+		record := models.IdempotencyRecord{
+			UserID:      expectedUser.ID,
+			Key:         "key-1",
+			Fingerprint: "fingerprint",
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Hour),
+		}
+
+		_, reserved, err := idempotencyDB.ReserveIdempotencyKey(ctx, record)
+		if err != nil || !reserved {
+			t.Fatalf("failed to reserve key; reserved: %v, error: %v", reserved, err)
+		}
+
+		existing, reserved, err := idempotencyDB.ReserveIdempotencyKey(ctx, record)
+		if err != nil || reserved || existing.StatusCode != 0 {
+			t.Errorf("key reserved twice; reserved: %v, record: %+v, error: %v", reserved, existing, err)
+		}
+
+		record.StatusCode = 201
+		record.Headers = map[string]string{"Content-Type": "application/json"}
+		record.Body = []byte(`{"id": 1}`)
+		if err := idempotencyDB.CompleteIdempotencyKey(ctx, record); err != nil {
+			t.Errorf("failed to complete key with error: %v", err)
+		}
+
+		existing, _, err = idempotencyDB.ReserveIdempotencyKey(ctx, record)
+		if err != nil || existing.StatusCode != 201 || string(existing.Body) != `{"id": 1}` ||
+			existing.Headers["Content-Type"] != "application/json" {
+			t.Errorf("stored response is corrupted; actual: %+v, error: %v", existing, err)
+		}
+
+		if err := idempotencyDB.ReleaseIdempotencyKey(ctx, expectedUser.ID, record.Key); err != nil {
+			t.Errorf("failed to release key with error: %v", err)
+		}
+
+		_, reserved, err = idempotencyDB.ReserveIdempotencyKey(ctx, record)
+		if err != nil || !reserved {
+			t.Errorf("released key is not reusable; reserved: %v, error: %v", reserved, err)
+		}
+
+		purged, err := idempotencyDB.PurgeExpiredIdempotencyKeys(ctx, now.Add(2*time.Hour))
+		if err != nil || purged != 1 {
+			t.Errorf("expired keys are not purged; purged: %d, error: %v", purged, err)
+		}
+	})
+
 	// Тестування отримання користувача за ім'ям, та за ім'ям і паролем
 	// Результат користувач повинен бути однаковим при кожному отримані з бд
 	t.Run("get user by username and get user by username and password", func(t *testing.T) {
-		userGet1, err := userDB.GetUserByUsername(newUser.Username)
+		ctx := t.Context()
+
+		userGet1, err := userDB.GetUserByUsername(ctx, newUser.Username)
 		if err != nil {
 			t.Errorf("failed to get user with error: %v", err)
 		}
 
-		userGet2, err := userDB.GetUserByUsernameAndPassword(newUser.Username, newUser.Password)
+		userGet2, err := userDB.GetUserByUsernameAndPassword(ctx, newUser.Username, newUser.Password)
 		if err != nil {
 			t.Errorf("failed to get user with error: %v", err)
 		}
@@ -0,0 +1,959 @@
+// Package dbtest містить спільний набір тестів відповідності для реалізацій database.ExpenseDB,
+// database.UserDB, database.ExpenseHistoryDB та database.IdempotencyDB. Кожне нове сховище
+// перевіряється тими самими очікуваннями:
+//
+//	func TestMySQLConformance(t *testing.T) {
+//		dbtest.RunExpenseDBSuite(t, newMySQLStores)
+//		dbtest.RunUserDBSuite(t, newMySQLStores)
+//		dbtest.RunExpenseHistoryDBSuite(t, newMySQLStores)
+//		dbtest.RunIdempotencyDBSuite(t, newMySQLStores)
+//	}
+//
+// NewStorage відкриває для цих та інших тестів чисту базу даних СУБД, обраної через TEST_DB_DRIVER.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// Stores - сховища, що працюють з однією базою даних
+type Stores struct {
+	Expenses    database.ExpenseDB
+	Users       database.UserDB
+	History     database.ExpenseHistoryDB
+	Idempotency database.IdempotencyDB
+}
+
+// Factory створює сховища над новою порожньою базою даних. Кожен підтест отримує власну базу;
+// звільнення ресурсів реєструється через t.Cleanup.
+type Factory func(t *testing.T) Stores
+
+// concurrentWriters - кількість горутин у тестах одночасного запису
+const concurrentWriters = 20
+
+// RunExpenseDBSuite перевіряє реалізацію database.ExpenseDB
+func RunExpenseDBSuite(t *testing.T, newStores Factory) {
+	t.Run("AddExpense assigns increasing ids and version 1", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		first := addExpense(t, s, userID, "food", 10)
+		second := addExpense(t, s, userID, "rent", 20)
+		if second <= first {
+			t.Errorf("ids are not increasing: %d, %d", first, second)
+		}
+
+		expense, err := s.Expenses.GetExpenseByID(ctx, userID, first)
+		if err != nil {
+			t.Fatalf("GetExpenseByID: %v", err)
+		}
+		if expense.ID != first || expense.UserID != userID || expense.Version != 1 ||
+			expense.Category != "food" || expense.Amount != 10 || expense.DeletedAt != nil {
+			t.Errorf("stored expense is corrupted: %+v", expense)
+		}
+	})
+
+	t.Run("AddExpense for missing user fails", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+
+		_, err := s.Expenses.AddExpense(ctx, models.Expense{Category: "food", Amount: 1, Date: testDate, UserID: 42}, by(42))
+		if err == nil {
+			t.Error("expense of missing user was stored")
+		}
+	})
+
+	t.Run("GetUserExpenses is ordered by id and limited to the owner", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+
+		var ids []int
+		for i := 0; i < 5; i++ {
+			ids = append(ids, addExpense(t, s, owner, fmt.Sprintf("category %d", i), i))
+			addExpense(t, s, other, "foreign", 100)
+		}
+
+		expenses, err := s.Expenses.GetUserExpenses(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetUserExpenses: %v", err)
+		}
+		if len(expenses) != len(ids) {
+			t.Fatalf("got %d expenses, want %d", len(expenses), len(ids))
+		}
+		for i, expense := range expenses {
+			if expense.ID != ids[i] || expense.Category != fmt.Sprintf("category %d", i) || expense.Version != 1 {
+				t.Errorf("expense %d is wrong: %+v", i, expense)
+			}
+		}
+	})
+
+	t.Run("GetUserExpenses of user without expenses is empty", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		expenses, err := s.Expenses.GetUserExpenses(ctx, userID)
+		if err != nil || len(expenses) != 0 {
+			t.Errorf("got %v, %v; want no expenses", expenses, err)
+		}
+	})
+
+	t.Run("GetExpenseByID hides foreign and missing expenses", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		id := addExpense(t, s, owner, "food", 10)
+
+		if _, err := s.Expenses.GetExpenseByID(ctx, other, id); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("foreign expense: got %v, want %v", err, database.ErrNotFound)
+		}
+		if _, err := s.Expenses.GetExpenseByID(ctx, owner, id+1000); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("missing expense: got %v, want %v", err, database.ErrNotFound)
+		}
+	})
+
+	t.Run("UpdateUserExpenses checks the owner and the version", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		id := addExpense(t, s, userID, "food", 10)
+
+		update := models.Expense{ID: id, Category: "Їжа та напої", Amount: 0, Date: testDate.AddDate(0, 0, 1), Version: 1}
+		if err := s.Expenses.UpdateUserExpenses(ctx, other, update, by(other)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("foreign update: got %v, want %v", err, database.ErrNotFound)
+		}
+		if err := s.Expenses.UpdateUserExpenses(ctx, userID, update, by(userID)); err != nil {
+			t.Fatalf("UpdateUserExpenses: %v", err)
+		}
+
+		expense, err := s.Expenses.GetExpenseByID(ctx, userID, id)
+		if err != nil {
+			t.Fatalf("GetExpenseByID: %v", err)
+		}
+		if expense.Category != update.Category || expense.Amount != 0 || !expense.Date.Equal(update.Date) ||
+			expense.Version != 2 || expense.UserID != userID {
+			t.Errorf("updated expense is corrupted: %+v", expense)
+		}
+
+		if err := s.Expenses.UpdateUserExpenses(ctx, userID, update, by(userID)); !errors.Is(err, database.ErrVersionConflict) {
+			t.Errorf("stale update: got %v, want %v", err, database.ErrVersionConflict)
+		}
+
+		update.ID = id + 1000
+		if err := s.Expenses.UpdateUserExpenses(ctx, userID, update, by(userID)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("missing expense: got %v, want %v", err, database.ErrNotFound)
+		}
+	})
+
+	t.Run("DeleteExpense moves the expense to the trash", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		id := addExpense(t, s, userID, "food", 10)
+
+		if err := s.Expenses.DeleteExpense(ctx, other, id, 1, by(other)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("foreign delete: got %v, want %v", err, database.ErrNotFound)
+		}
+		if err := s.Expenses.DeleteExpense(ctx, userID, id, 2, by(userID)); !errors.Is(err, database.ErrVersionConflict) {
+			t.Errorf("stale delete: got %v, want %v", err, database.ErrVersionConflict)
+		}
+		if err := s.Expenses.DeleteExpense(ctx, userID, id, 1, by(userID)); err != nil {
+			t.Fatalf("DeleteExpense: %v", err)
+		}
+		if err := s.Expenses.DeleteExpense(ctx, userID, id, 2, by(userID)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("deleted twice: got %v, want %v", err, database.ErrNotFound)
+		}
+		if err := s.Expenses.DeleteExpense(ctx, userID, id+1000, 1, by(userID)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("missing expense: got %v, want %v", err, database.ErrNotFound)
+		}
+
+		if _, err := s.Expenses.GetExpenseByID(ctx, userID, id); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("deleted expense is visible: %v", err)
+		}
+		if expenses, _ := s.Expenses.GetUserExpenses(ctx, userID); len(expenses) != 0 {
+			t.Errorf("deleted expense is listed: %+v", expenses)
+		}
+
+		trash, err := s.Expenses.GetUserTrash(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUserTrash: %v", err)
+		}
+		if len(trash) != 1 || trash[0].ID != id || trash[0].Version != 2 || trash[0].DeletedAt == nil {
+			t.Errorf("trash is corrupted: %+v", trash)
+		}
+	})
+
+	t.Run("changes are not saved without their revisions", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+		id := addExpense(t, s, userID, "food", 10)
+		trashed := addExpense(t, s, userID, "rent", 20)
+		if err := s.Expenses.DeleteExpense(ctx, userID, trashed, 1, by(userID)); err != nil {
+			t.Fatalf("DeleteExpense: %v", err)
+		}
+
+		// Ревізію від імені неіснуючого користувача не можна записати (зовнішній ключ)
+		missing := by(userID + 1000)
+		if _, err := s.Expenses.AddExpense(ctx, models.Expense{Category: "fun", Amount: 1, Date: testDate, UserID: userID}, missing); err == nil {
+			t.Error("AddExpense succeeded without a revision")
+		}
+		update := models.Expense{ID: id, Category: "changed", Amount: 1, Date: testDate, Version: 1}
+		if err := s.Expenses.UpdateUserExpenses(ctx, userID, update, missing); err == nil {
+			t.Error("UpdateUserExpenses succeeded without a revision")
+		}
+		if err := s.Expenses.DeleteExpense(ctx, userID, id, 1, missing); err == nil {
+			t.Error("DeleteExpense succeeded without a revision")
+		}
+		if err := s.Expenses.RestoreExpense(ctx, userID, trashed, missing); err == nil {
+			t.Error("RestoreExpense succeeded without a revision")
+		}
+		if err := s.Expenses.PurgeExpense(ctx, userID, trashed, missing); err == nil {
+			t.Error("PurgeExpense succeeded without a revision")
+		}
+		ops := []models.BatchOperation{
+			{Op: models.BatchCreate, Expense: models.Expense{Category: "fun", Amount: 1, Date: testDate}},
+			{Op: models.BatchUpdate, ID: id, Version: 1, Expense: update},
+		}
+		if _, err := s.Expenses.ApplyBatch(ctx, userID, ops, false, missing); err == nil {
+			t.Error("ApplyBatch succeeded without revisions")
+		}
+
+		expenses, err := s.Expenses.GetUserExpenses(ctx, userID)
+		if err != nil || len(expenses) != 1 || expenses[0].Category != "food" || expenses[0].Version != 1 {
+			t.Errorf("failed changes were saved: %+v, %v", expenses, err)
+		}
+		trash, err := s.Expenses.GetUserTrash(ctx, userID)
+		if err != nil || len(trash) != 1 || trash[0].ID != trashed || trash[0].Version != 2 {
+			t.Errorf("failed changes of the trash were saved: %+v, %v", trash, err)
+		}
+	})
+
+	t.Run("GetUserTrash lists the most recently deleted first", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		var ids []int
+		for i := 0; i < 2; i++ {
+			if i > 0 {
+				// MySQL TIMESTAMP зберігає час з точністю до секунди
+				time.Sleep(1100 * time.Millisecond)
+			}
+			id := addExpense(t, s, userID, "food", i)
+			if err := s.Expenses.DeleteExpense(ctx, userID, id, 1, by(userID)); err != nil {
+				t.Fatalf("DeleteExpense: %v", err)
+			}
+			ids = append(ids, id)
+		}
+
+		trash, err := s.Expenses.GetUserTrash(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUserTrash: %v", err)
+		}
+		if len(trash) != 2 || trash[0].ID != ids[1] || trash[1].ID != ids[0] {
+			t.Errorf("trash order is wrong: %+v", trash)
+		}
+	})
+
+	t.Run("RestoreExpense and PurgeExpense are limited to the owner's trash", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		restored := addExpense(t, s, owner, "food", 10)
+		purged := addExpense(t, s, owner, "rent", 20)
+		active := addExpense(t, s, owner, "fun", 30)
+		for _, id := range []int{restored, purged} {
+			if err := s.Expenses.DeleteExpense(ctx, owner, id, 1, by(owner)); err != nil {
+				t.Fatalf("DeleteExpense: %v", err)
+			}
+		}
+
+		for _, id := range []int{restored, purged} {
+			if err := s.Expenses.RestoreExpense(ctx, other, id, by(other)); !errors.Is(err, database.ErrNotFound) {
+				t.Errorf("foreign restore: got %v, want %v", err, database.ErrNotFound)
+			}
+			if err := s.Expenses.PurgeExpense(ctx, other, id, by(other)); !errors.Is(err, database.ErrNotFound) {
+				t.Errorf("foreign purge: got %v, want %v", err, database.ErrNotFound)
+			}
+		}
+		if err := s.Expenses.RestoreExpense(ctx, owner, active, by(owner)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("restore of active expense: got %v, want %v", err, database.ErrNotFound)
+		}
+		if err := s.Expenses.PurgeExpense(ctx, owner, active, by(owner)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("purge of active expense: got %v, want %v", err, database.ErrNotFound)
+		}
+
+		if err := s.Expenses.RestoreExpense(ctx, owner, restored, by(owner)); err != nil {
+			t.Fatalf("RestoreExpense: %v", err)
+		}
+		expense, err := s.Expenses.GetExpenseByID(ctx, owner, restored)
+		if err != nil || expense.Version != 3 || expense.DeletedAt != nil {
+			t.Errorf("restored expense is wrong: %+v, %v", expense, err)
+		}
+
+		if err := s.Expenses.PurgeExpense(ctx, owner, purged, by(owner)); err != nil {
+			t.Fatalf("PurgeExpense: %v", err)
+		}
+		if err := s.Expenses.RestoreExpense(ctx, owner, purged, by(owner)); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("restore of purged expense: got %v, want %v", err, database.ErrNotFound)
+		}
+		if trash, _ := s.Expenses.GetUserTrash(ctx, owner); len(trash) != 0 {
+			t.Errorf("trash is not empty: %+v", trash)
+		}
+	})
+
+	t.Run("PurgeTrash removes only old deleted expenses", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+		active := addExpense(t, s, userID, "food", 10)
+		deleted := addExpense(t, s, userID, "rent", 20)
+		if err := s.Expenses.DeleteExpense(ctx, userID, deleted, 1, by(userID)); err != nil {
+			t.Fatalf("DeleteExpense: %v", err)
+		}
+
+		purged, err := s.Expenses.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+		if err != nil || purged != 0 {
+			t.Errorf("recently deleted expense purged: %d, %v", purged, err)
+		}
+
+		purged, err = s.Expenses.PurgeTrash(ctx, time.Now().Add(time.Hour))
+		if err != nil || purged != 1 {
+			t.Errorf("got %d purged, %v; want 1", purged, err)
+		}
+
+		if _, err := s.Expenses.GetExpenseByID(ctx, userID, active); err != nil {
+			t.Errorf("active expense purged: %v", err)
+		}
+
+		history, err := s.History.GetExpenseHistory(ctx, userID, deleted)
+		if err != nil || len(history) != 3 {
+			t.Fatalf("GetExpenseHistory: %+v, %v", history, err)
+		}
+		last := history[2]
+		if last.Action != models.ActionPurge || last.ActorID != userID || len(last.After) != 0 || snapshot(t, last.Before).Category != "rent" {
+			t.Errorf("purge revision is corrupted: %+v", last)
+		}
+	})
+
+	t.Run("ApplyBatch keeps the order of consecutive creates", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		var ops []models.BatchOperation
+		for i := 0; i < 7; i++ {
+			ops = append(ops, models.BatchOperation{Op: models.BatchCreate, Expense: models.Expense{Category: fmt.Sprintf("bulk %d", i), Amount: i, Date: testDate}})
+		}
+		results, err := s.Expenses.ApplyBatch(ctx, userID, ops, true, by(userID))
+		if err != nil || len(results) != len(ops) {
+			t.Fatalf("ApplyBatch: %v, %v", results, err)
+		}
+		for i, result := range results {
+			if result.Err != nil || result.After == nil {
+				t.Fatalf("create %d failed: %+v", i, result)
+			}
+			expense, err := s.Expenses.GetExpenseByID(ctx, userID, result.After.ID)
+			if err != nil || expense.Category != ops[i].Expense.Category || expense.UserID != userID {
+				t.Errorf("expense %d: got %+v, %v", i, expense, err)
+			}
+		}
+	})
+
+	t.Run("ApplyBatch in atomic mode changes nothing on failure", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		id := addExpense(t, s, owner, "food", 10)
+		foreign := addExpense(t, s, other, "foreign", 10)
+
+		ops := []models.BatchOperation{
+			{Op: models.BatchCreate, Expense: models.Expense{Category: "new", Amount: 1, Date: testDate}},
+			{Op: models.BatchUpdate, ID: id, Version: 1, Expense: models.Expense{Category: "changed", Amount: 2, Date: testDate}},
+			{Op: models.BatchDelete, ID: foreign, Version: 1},
+		}
+		results, err := s.Expenses.ApplyBatch(ctx, owner, ops, true, by(owner))
+		if err != nil {
+			t.Fatalf("ApplyBatch: %v", err)
+		}
+		if len(results) != len(ops) || !errors.Is(results[0].Err, database.ErrBatchAborted) ||
+			!errors.Is(results[1].Err, database.ErrBatchAborted) || !errors.Is(results[2].Err, database.ErrNotFound) {
+			t.Errorf("results are wrong: %+v", results)
+		}
+
+		expenses, _ := s.Expenses.GetUserExpenses(ctx, owner)
+		if len(expenses) != 1 || expenses[0].Category != "food" || expenses[0].Version != 1 {
+			t.Errorf("aborted batch changed data: %+v", expenses)
+		}
+		if _, err := s.Expenses.GetExpenseByID(ctx, other, foreign); err != nil {
+			t.Errorf("foreign expense changed: %v", err)
+		}
+	})
+
+	t.Run("ApplyBatch in partial mode applies successful operations", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+		updated := addExpense(t, s, userID, "food", 10)
+		deleted := addExpense(t, s, userID, "rent", 20)
+
+		ops := []models.BatchOperation{
+			{Op: models.BatchCreate, Expense: models.Expense{Category: "first", Amount: 1, Date: testDate}},
+			{Op: models.BatchCreate, Expense: models.Expense{Category: "second", Amount: 2, Date: testDate}},
+			{Op: models.BatchUpdate, ID: updated, Version: 5, Expense: models.Expense{Category: "stale", Amount: 3, Date: testDate}},
+			{Op: models.BatchUpdate, ID: updated, Version: 1, Expense: models.Expense{Category: "changed", Amount: 4, Date: testDate}},
+			{Op: models.BatchDelete, ID: deleted, Version: 1},
+		}
+		results, err := s.Expenses.ApplyBatch(ctx, userID, ops, false, by(userID))
+		if err != nil {
+			t.Fatalf("ApplyBatch: %v", err)
+		}
+		if len(results) != len(ops) {
+			t.Fatalf("got %d results, want %d", len(results), len(ops))
+		}
+
+		for _, i := range []int{0, 1} {
+			if results[i].Err != nil || results[i].After == nil || results[i].After.Version != 1 ||
+				results[i].After.Category != ops[i].Expense.Category {
+				t.Errorf("create %d: %+v", i, results[i])
+			}
+		}
+		if results[1].After != nil && results[0].After != nil && results[1].After.ID <= results[0].After.ID {
+			t.Errorf("created ids are not increasing: %d, %d", results[0].After.ID, results[1].After.ID)
+		}
+		if !errors.Is(results[2].Err, database.ErrVersionConflict) {
+			t.Errorf("stale update: got %v, want %v", results[2].Err, database.ErrVersionConflict)
+		}
+		if results[3].Err != nil || results[3].Before.Category != "food" || results[3].After.Category != "changed" ||
+			results[3].After.Version != 2 {
+			t.Errorf("update: %+v", results[3])
+		}
+		if results[4].Err != nil || results[4].After.DeletedAt == nil || results[4].After.Version != 2 {
+			t.Errorf("delete: %+v", results[4])
+		}
+
+		expenses, _ := s.Expenses.GetUserExpenses(ctx, userID)
+		if len(expenses) != 3 {
+			t.Errorf("got %d expenses after batch, want 3: %+v", len(expenses), expenses)
+		}
+	})
+
+	t.Run("concurrent AddExpense calls get unique ids", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		ids := make([]int, concurrentWriters)
+		errs := make([]error, concurrentWriters)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ids[i], errs[i] = s.Expenses.AddExpense(ctx, models.Expense{Category: "food", Amount: i, Date: testDate, UserID: userID}, by(userID))
+			}(i)
+		}
+		wg.Wait()
+
+		seen := make(map[int]bool)
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("AddExpense %d: %v", i, err)
+			}
+			if seen[ids[i]] {
+				t.Errorf("id %d assigned twice", ids[i])
+			}
+			seen[ids[i]] = true
+		}
+
+		expenses, _ := s.Expenses.GetUserExpenses(ctx, userID)
+		if len(expenses) != concurrentWriters {
+			t.Errorf("got %d expenses, want %d", len(expenses), concurrentWriters)
+		}
+	})
+
+	t.Run("concurrent updates of one version succeed once", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+		id := addExpense(t, s, userID, "food", 10)
+
+		errs := make([]error, concurrentWriters)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = s.Expenses.UpdateUserExpenses(ctx, userID, models.Expense{ID: id, Category: "writer", Amount: i, Date: testDate, Version: 1}, by(userID))
+			}(i)
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			case !errors.Is(err, database.ErrVersionConflict):
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if succeeded != 1 {
+			t.Errorf("%d concurrent updates succeeded, want 1", succeeded)
+		}
+
+		expense, err := s.Expenses.GetExpenseByID(ctx, userID, id)
+		if err != nil || expense.Version != 2 {
+			t.Errorf("got %+v, %v; want version 2", expense, err)
+		}
+	})
+
+	t.Run("cancelled context stops the operation", func(t *testing.T) {
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		if _, err := s.Expenses.AddExpense(ctx, models.Expense{Category: "food", Amount: 1, Date: testDate, UserID: userID}, by(userID)); !errors.Is(err, context.Canceled) {
+			t.Errorf("AddExpense: got %v, want %v", err, context.Canceled)
+		}
+		if _, err := s.Expenses.GetUserExpenses(ctx, userID); !errors.Is(err, context.Canceled) {
+			t.Errorf("GetUserExpenses: got %v, want %v", err, context.Canceled)
+		}
+
+		// Скасована операція нічого не змінила
+		expenses, err := s.Expenses.GetUserExpenses(t.Context(), userID)
+		if err != nil || len(expenses) != 0 {
+			t.Errorf("got %v, %v; want no expenses", expenses, err)
+		}
+	})
+}
+
+// RunUserDBSuite перевіряє реалізацію database.UserDB
+func RunUserDBSuite(t *testing.T, newStores Factory) {
+	t.Run("AddUser assigns ids and hides the password", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		first := addUser(t, s, "first")
+		second := addUser(t, s, "second")
+		if second <= first {
+			t.Errorf("ids are not increasing: %d, %d", first, second)
+		}
+
+		user, err := s.Users.GetUserByID(ctx, first)
+		if err != nil {
+			t.Fatalf("GetUserByID: %v", err)
+		}
+		if user != (models.User{ID: first, Username: "first"}) {
+			t.Errorf("got %+v", user)
+		}
+	})
+
+	t.Run("lookups by username and password", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		id := addUser(t, s, "owner")
+		expected := models.User{ID: id, Username: "owner"}
+
+		user, err := s.Users.GetUserByUsername(ctx, "owner")
+		if err != nil || user != expected {
+			t.Errorf("GetUserByUsername: got %+v, %v", user, err)
+		}
+
+		user, err = s.Users.GetUserByUsernameAndPassword(ctx, "owner", password)
+		if err != nil || user != expected {
+			t.Errorf("GetUserByUsernameAndPassword: got %+v, %v", user, err)
+		}
+	})
+
+	t.Run("missing users", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		id := addUser(t, s, "owner")
+
+		// Обробник входу відрізняє невірні дані (sql.ErrNoRows -> 401) від помилок сховища
+		if _, err := s.Users.GetUserByUsernameAndPassword(ctx, "owner", "wrong"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("wrong password: got %v, want %v", err, sql.ErrNoRows)
+		}
+		if _, err := s.Users.GetUserByUsernameAndPassword(ctx, "nobody", password); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("missing username: got %v, want %v", err, sql.ErrNoRows)
+		}
+		if _, err := s.Users.GetUserByUsername(ctx, "nobody"); err == nil {
+			t.Error("GetUserByUsername found a missing user")
+		}
+		if _, err := s.Users.GetUserByID(ctx, id+1000); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("GetUserByID of a missing user: got %v, want %v", err, database.ErrNotFound)
+		}
+	})
+
+	t.Run("cancelled context stops the operation", func(t *testing.T) {
+		s := newStores(t)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		if err := s.Users.AddUser(ctx, models.User{Username: "owner", Password: password}); !errors.Is(err, context.Canceled) {
+			t.Errorf("AddUser: got %v, want %v", err, context.Canceled)
+		}
+		if _, err := s.Users.GetUserByUsername(t.Context(), "owner"); err == nil {
+			t.Error("user was added with a cancelled context")
+		}
+	})
+}
+
+// RunExpenseHistoryDBSuite перевіряє реалізацію database.ExpenseHistoryDB
+func RunExpenseHistoryDBSuite(t *testing.T, newStores Factory) {
+	t.Run("GetExpenseHistory is chronological and limited to the owner", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		id := addExpense(t, s, owner, "food", 10)
+
+		update := models.Expense{ID: id, Category: "changed", Amount: 20, Date: testDate, Version: 1}
+		if err := s.Expenses.UpdateUserExpenses(ctx, owner, update, by(owner)); err != nil {
+			t.Fatalf("UpdateUserExpenses: %v", err)
+		}
+		if err := s.Expenses.DeleteExpense(ctx, owner, id, 2, by(owner)); err != nil {
+			t.Fatalf("DeleteExpense: %v", err)
+		}
+
+		history, err := s.History.GetExpenseHistory(ctx, owner, id)
+		if err != nil {
+			t.Fatalf("GetExpenseHistory: %v", err)
+		}
+
+		expected := []string{models.ActionCreate, models.ActionUpdate, models.ActionDelete}
+		if len(history) != len(expected) {
+			t.Fatalf("got %d revisions, want %d: %+v", len(history), len(expected), history)
+		}
+		for i, revision := range history {
+			if revision.Action != expected[i] || revision.ExpenseID != id || revision.UserID != owner ||
+				revision.ActorID != owner || revision.RequestID != "dbtest" {
+				t.Errorf("revision %d is wrong: %+v", i, revision)
+			}
+			if i > 0 && revision.ID <= history[i-1].ID {
+				t.Errorf("revision ids are not increasing: %d, %d", history[i-1].ID, revision.ID)
+			}
+		}
+
+		// Стан до та після зміни зберігаються знімками витрати
+		if len(history[0].Before) != 0 {
+			t.Errorf("create revision has a state before: %s", history[0].Before)
+		}
+		before, after := snapshot(t, history[1].Before), snapshot(t, history[1].After)
+		if before.Category != "food" || before.Version != 1 || after.Category != "changed" || after.Version != 2 {
+			t.Errorf("update snapshots are wrong: %+v -> %+v", before, after)
+		}
+		if deleted := snapshot(t, history[2].After); deleted.DeletedAt == nil {
+			t.Errorf("delete snapshot is not in the trash: %+v", deleted)
+		}
+
+		foreign, err := s.History.GetExpenseHistory(ctx, other, id)
+		if err != nil || len(foreign) != 0 {
+			t.Errorf("history of a foreign expense: %+v, %v", foreign, err)
+		}
+	})
+
+	t.Run("audited change and GetRevision round-trip", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		admin := addUser(t, s, "admin")
+		id := addExpense(t, s, owner, "food", 10)
+
+		audit := database.Audit{ActorID: admin, RequestID: "req-1", Action: models.ActionRevert}
+		changed := models.Expense{ID: id, Category: "rent", Amount: 20, Date: testDate, UserID: owner, Version: 1}
+		if err := s.Expenses.UpdateUserExpenses(ctx, owner, changed, audit); err != nil {
+			t.Fatalf("UpdateUserExpenses: %v", err)
+		}
+
+		history, err := s.History.GetExpenseHistory(ctx, owner, id)
+		if err != nil || len(history) != 2 {
+			t.Fatalf("GetExpenseHistory: %+v, %v", history, err)
+		}
+
+		stored, err := s.History.GetRevision(ctx, owner, id, history[1].ID)
+		if err != nil {
+			t.Fatalf("GetRevision: %v", err)
+		}
+		if stored.ActorID != admin || stored.Action != models.ActionRevert || stored.RequestID != "req-1" || stored.CreatedAt.IsZero() ||
+			snapshot(t, stored.Before).Category != "food" || snapshot(t, stored.After).Category != "rent" {
+			t.Errorf("stored revision is corrupted: %+v", stored)
+		}
+	})
+
+	t.Run("GetRevision of a missing or foreign revision returns ErrNotFound", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+		id := addExpense(t, s, owner, "food", 10)
+
+		history, err := s.History.GetExpenseHistory(ctx, owner, id)
+		if err != nil || len(history) != 1 {
+			t.Fatalf("GetExpenseHistory: %+v, %v", history, err)
+		}
+		revisionID := history[0].ID
+
+		for name, lookup := range map[string][3]int{
+			"missing revision": {owner, id, revisionID + 1000},
+			"other expense":    {owner, id + 1000, revisionID},
+			"other user":       {other, id, revisionID},
+		} {
+			if _, err := s.History.GetRevision(ctx, lookup[0], lookup[1], lookup[2]); !errors.Is(err, database.ErrNotFound) {
+				t.Errorf("%s: got %v, want %v", name, err, database.ErrNotFound)
+			}
+		}
+	})
+
+	t.Run("change by a missing actor is rolled back", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		id := addExpense(t, s, owner, "food", 10)
+
+		changed := models.Expense{ID: id, Category: "rent", Amount: 20, Date: testDate, UserID: owner, Version: 1}
+		if err := s.Expenses.UpdateUserExpenses(ctx, owner, changed, by(owner+1000)); err == nil {
+			t.Error("revision of a missing actor was stored")
+		}
+
+		expense, err := s.Expenses.GetExpenseByID(ctx, owner, id)
+		if err != nil || expense.Category != "food" || expense.Version != 1 {
+			t.Errorf("change without a revision was kept: %+v, %v", expense, err)
+		}
+	})
+}
+
+// RunIdempotencyDBSuite перевіряє реалізацію database.IdempotencyDB
+func RunIdempotencyDBSuite(t *testing.T, newStores Factory) {
+	t.Run("ReserveIdempotencyKey reserves a key once", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		record := idempotencyRecord(userID, "key", testDate)
+		reserved, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, record)
+		if err != nil || !ok || reserved.Fingerprint != record.Fingerprint || reserved.StatusCode != 0 {
+			t.Fatalf("first reservation: %+v, %v, %v", reserved, ok, err)
+		}
+
+		retry := record
+		retry.Fingerprint = "other"
+		existing, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, retry)
+		if err != nil || ok {
+			t.Fatalf("second reservation: %v, %v", ok, err)
+		}
+		if existing.Fingerprint != record.Fingerprint || existing.StatusCode != 0 {
+			t.Errorf("second reservation returned %+v", existing)
+		}
+	})
+
+	t.Run("CompleteIdempotencyKey stores the response for replay", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		record := idempotencyRecord(userID, "key", testDate)
+		if _, _, err := s.Idempotency.ReserveIdempotencyKey(ctx, record); err != nil {
+			t.Fatalf("ReserveIdempotencyKey: %v", err)
+		}
+
+		record.StatusCode = 201
+		record.Headers = map[string]string{"Location": "/api/v1/expenses/1"}
+		record.Body = []byte(`{"id":1}`)
+		if err := s.Idempotency.CompleteIdempotencyKey(ctx, record); err != nil {
+			t.Fatalf("CompleteIdempotencyKey: %v", err)
+		}
+
+		stored, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(userID, "key", testDate))
+		if err != nil || ok {
+			t.Fatalf("replay: %v, %v", ok, err)
+		}
+		if stored.StatusCode != 201 || stored.Headers["Location"] != "/api/v1/expenses/1" || string(stored.Body) != `{"id":1}` {
+			t.Errorf("stored response is corrupted: %+v", stored)
+		}
+
+		missing := idempotencyRecord(userID, "missing", testDate)
+		missing.StatusCode = 201
+		if err := s.Idempotency.CompleteIdempotencyKey(ctx, missing); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("completing a missing key: got %v, want %v", err, database.ErrNotFound)
+		}
+	})
+
+	t.Run("keys are limited to the user", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		owner := addUser(t, s, "owner")
+		other := addUser(t, s, "other")
+
+		if _, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(owner, "key", testDate)); err != nil || !ok {
+			t.Fatalf("owner reservation: %v, %v", ok, err)
+		}
+		if _, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(other, "key", testDate)); err != nil || !ok {
+			t.Errorf("other user's reservation: %v, %v", ok, err)
+		}
+	})
+
+	t.Run("released and expired keys can be reserved again", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		for _, key := range []string{"released", "expired"} {
+			if _, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(userID, key, testDate)); err != nil || !ok {
+				t.Fatalf("reservation of %s: %v, %v", key, ok, err)
+			}
+		}
+		if err := s.Idempotency.ReleaseIdempotencyKey(ctx, userID, "released"); err != nil {
+			t.Fatalf("ReleaseIdempotencyKey: %v", err)
+		}
+
+		if _, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(userID, "released", testDate)); err != nil || !ok {
+			t.Errorf("released key: %v, %v", ok, err)
+		}
+		later := testDate.Add(2 * time.Hour)
+		if _, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(userID, "expired", later)); err != nil || !ok {
+			t.Errorf("expired key: %v, %v", ok, err)
+		}
+	})
+
+	t.Run("PurgeExpiredIdempotencyKeys removes only expired keys", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+		userID := addUser(t, s, "owner")
+
+		for key, createdAt := range map[string]time.Time{"old": testDate, "fresh": testDate.Add(2 * time.Hour)} {
+			if _, _, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(userID, key, createdAt)); err != nil {
+				t.Fatalf("ReserveIdempotencyKey: %v", err)
+			}
+		}
+
+		purged, err := s.Idempotency.PurgeExpiredIdempotencyKeys(ctx, testDate.Add(90*time.Minute))
+		if err != nil || purged != 1 {
+			t.Errorf("PurgeExpiredIdempotencyKeys: got %d, %v, want 1", purged, err)
+		}
+		if _, ok, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(userID, "fresh", testDate.Add(2*time.Hour))); err != nil || ok {
+			t.Errorf("fresh key was purged: %v, %v", ok, err)
+		}
+	})
+
+	t.Run("ReserveIdempotencyKey for a missing user fails", func(t *testing.T) {
+		ctx := t.Context()
+
+		s := newStores(t)
+
+		if _, _, err := s.Idempotency.ReserveIdempotencyKey(ctx, idempotencyRecord(42, "key", testDate)); err == nil {
+			t.Error("key of a missing user was reserved")
+		}
+	})
+}
+
+const password = "12345"
+
+var testDate = time.Date(2023, 5, 27, 0, 0, 0, 0, time.UTC)
+
+func addUser(t *testing.T, s Stores, username string) int {
+	t.Helper()
+
+	ctx := t.Context()
+
+	if err := s.Users.AddUser(ctx, models.User{Username: username, Password: password}); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+
+	user, err := s.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		t.Fatalf("GetUserByUsername: %v", err)
+	}
+
+	return user.ID
+}
+
+func addExpense(t *testing.T, s Stores, userID int, category string, amount int) int {
+	t.Helper()
+
+	ctx := t.Context()
+
+	id, err := s.Expenses.AddExpense(ctx, models.Expense{Category: category, Amount: amount, Date: testDate, UserID: userID}, by(userID))
+	if err != nil {
+		t.Fatalf("AddExpense: %v", err)
+	}
+
+	return id
+}
+
+// by описує зміну, яку користувач userID робить сам
+func by(userID int) database.Audit {
+	return database.Audit{ActorID: userID, RequestID: "dbtest"}
+}
+
+// snapshot розбирає знімок витрати з ревізії історії
+func snapshot(t *testing.T, raw json.RawMessage) models.Expense {
+	t.Helper()
+
+	var expense models.Expense
+	if err := json.Unmarshal(raw, &expense); err != nil {
+		t.Fatalf("snapshot %q: %v", raw, err)
+	}
+	return expense
+}
+
+// idempotencyRecord описує ключ, що діє годину з моменту createdAt
+func idempotencyRecord(userID int, key string, createdAt time.Time) models.IdempotencyRecord {
+	return models.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: "fingerprint",
+		CreatedAt:   createdAt,
+		ExpiresAt:   createdAt.Add(time.Hour),
+	}
+}
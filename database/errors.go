@@ -0,0 +1,9 @@
+package database
+
+import "errors"
+
+// ErrNotFound повертається, коли запис не знайдено (або він належить іншому користувачу)
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionConflict повертається, коли запис було змінено після того, як клієнт його отримав
+var ErrVersionConflict = errors.New("record version conflict")
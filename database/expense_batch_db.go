@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// maxInsertRows обмежує кількість рядків в одному INSERT (MySQL та PostgreSQL допускають до 65535 параметрів у запиті)
+const maxInsertRows = 500
+
+// execer об'єднує *sql.DB та *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// batchCreateFunc зберігає кілька операцій створення одним запитом та заповнює їхні результати
+type batchCreateFunc func(ctx context.Context, tx *sql.Tx, userID int, ops []models.BatchOperation, results []BatchResult) error
+
+// batchApplyFunc виконує одну операцію пакета та заповнює її результат
+type batchApplyFunc func(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error
+
+// insertFunc зберігає витрати одним багаторядковим INSERT та повертає їхні ID у порядку витрат
+type insertFunc func(ctx context.Context, q execer, expenses []models.Expense) ([]int, error)
+
+func (db *MySQLExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error) {
+	return applyBatch(ctx, db.DB, userID, ops, atomic, audit, mysqlBatchCreate, mysqlBatchApply, mysqlInsertRevision)
+}
+
+// applyBatch - спільна для SQL-сховищ реалізація ExpenseDB.ApplyBatch; запити конкретної СУБД
+// виконують create, apply та insert
+func applyBatch(ctx context.Context, conn *sql.DB, userID int, ops []models.BatchOperation, atomic bool, audit Audit, create batchCreateFunc, apply batchApplyFunc, insert revisionInsertFunc) ([]BatchResult, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck // після Commit нічого не робить
+
+	results := make([]BatchResult, len(ops))
+	failed := false
+
+	for start := 0; start < len(ops) && !(atomic && failed); {
+		end := start + 1
+		if ops[start].Op == models.BatchCreate {
+			for end < len(ops) && end-start < maxInsertRows && ops[end].Op == models.BatchCreate {
+				end++
+			}
+		}
+
+		// Послідовні створення виконуються одним INSERT; якщо він не вдався,
+		// операції повторюються по одній, щоб визначити, яка саме з них помилкова
+		if end-start > 1 {
+			itemErr, err := inSavepoint(ctx, tx, func() error {
+				return create(ctx, tx, userID, ops[start:end], results[start:end])
+			})
+			if err != nil {
+				return nil, err
+			}
+			if itemErr == nil {
+				start = end
+				continue
+			}
+		}
+
+		for i := start; i < end && !(atomic && failed); i++ {
+			itemErr, err := inSavepoint(ctx, tx, func() error {
+				if ops[i].Op == models.BatchCreate {
+					return create(ctx, tx, userID, ops[i:i+1], results[i:i+1])
+				}
+				return apply(ctx, tx, userID, ops[i], &results[i])
+			})
+			if err != nil {
+				return nil, err
+			}
+			if itemErr != nil {
+				results[i] = BatchResult{Err: itemErr}
+				failed = true
+			}
+		}
+		start = end
+	}
+
+	// В атомарному режимі помилка однієї операції скасовує всі інші
+	if atomic && failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BatchResult{Err: ErrBatchAborted}
+			}
+		}
+		return results, nil
+	}
+
+	// Ревізії успішних операцій фіксуються разом з ними; помилка запису історії скасовує весь пакет
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		if err = audit.record(ctx, tx, insert, batchActions[ops[i].Op], result.Before, result.After); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// inSavepoint виконує fn у точці збереження транзакції; при помилці fn зміни відкочуються
+// до точки збереження, а транзакція залишається придатною. Друга помилка - помилка самої транзакції.
+func inSavepoint(ctx context.Context, tx *sql.Tx, fn func() error) (itemErr, err error) {
+	if _, err = tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+		return nil, err
+	}
+
+	if itemErr = fn(); itemErr != nil {
+		if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+		return itemErr, nil
+	}
+
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item")
+	return nil, err
+}
+
+func mysqlBatchCreate(ctx context.Context, tx *sql.Tx, userID int, ops []models.BatchOperation, results []BatchResult) error {
+	return batchCreate(ctx, tx, userID, ops, results, mysqlInsertExpenses)
+}
+
+// batchCreate зберігає витрати операцій створення функцією insert та заповнює результати
+func batchCreate(ctx context.Context, tx *sql.Tx, userID int, ops []models.BatchOperation, results []BatchResult, insert insertFunc) error {
+	expenses := make([]models.Expense, len(ops))
+	for i, op := range ops {
+		expenses[i] = op.Expense
+		expenses[i].UserID = userID
+	}
+
+	ids, err := insert(ctx, tx, expenses)
+	if err != nil {
+		return err
+	}
+
+	for i := range expenses {
+		created := expenses[i]
+		created.ID = ids[i]
+		created.Version = 1
+		created.RawDate = ""
+		results[i] = BatchResult{After: &created}
+	}
+
+	return nil
+}
+
+func mysqlBatchApply(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error {
+	// Для зміни та видалення рядок блокується до кінця транзакції
+	query := "SELECT id, amount, category, date, user_id, version FROM expenses WHERE id = ? AND user_id = ? AND deleted_at IS NULL FOR UPDATE"
+	var before models.Expense
+	err := tx.QueryRowContext(ctx, query, op.ID, userID).Scan(&before.ID, &before.Amount, &before.Category, &before.Date, &before.UserID, &before.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if before.Version != op.Version {
+		return ErrVersionConflict
+	}
+
+	after := before
+	after.Version++
+
+	switch op.Op {
+	case models.BatchUpdate:
+		after.Amount = op.Expense.Amount
+		after.Category = op.Expense.Category
+		after.Date = op.Expense.Date
+
+		query = "UPDATE expenses SET amount = ?, category = ?, date = ?, version = version + 1 WHERE id = ?"
+		_, err = tx.ExecContext(ctx, query, after.Amount, after.Category, after.Date, after.ID)
+	case models.BatchDelete:
+		deletedAt := time.Now().UTC()
+		after.DeletedAt = &deletedAt
+
+		query = "UPDATE expenses SET deleted_at = ?, version = version + 1 WHERE id = ?"
+		_, err = tx.ExecContext(ctx, query, deletedAt, after.ID)
+	default:
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	*result = BatchResult{Before: &before, After: &after}
+	return nil
+}
+
+// mysqlInsertExpenses зберігає витрати одним багаторядковим INSERT та повертає їхні ID.
+// MySQL повертає лише ID першого рядка; для INSERT з відомою кількістю рядків InnoDB виділяє
+// ID підряд з кроком auto_increment_increment, тож решта ID обчислюється від першого.
+func mysqlInsertExpenses(ctx context.Context, q execer, expenses []models.Expense) ([]int, error) {
+	var step int
+	if err := q.QueryRowContext(ctx, "SELECT @@auto_increment_increment").Scan(&step); err != nil {
+		return nil, err
+	}
+
+	query := "INSERT INTO expenses (amount, category, date, user_id) VALUES " +
+		strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(expenses)), ", ")
+
+	args := make([]interface{}, 0, 4*len(expenses))
+	for _, expense := range expenses {
+		args = append(args, expense.Amount, expense.Category, expense.Date, expense.UserID)
+	}
+
+	res, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(expenses))
+	for i := range ids {
+		ids[i] = int(first) + i*step
+	}
+
+	return ids, nil
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/ChomuCake/uni-golang-labs/models"
 	_ "github.com/go-sql-driver/mysql"
@@ -12,10 +14,11 @@ type MySQLExpenseDB struct {
 	DB *sql.DB
 }
 
-func (db *MySQLExpenseDB) GetUserExpenses(userID int) ([]models.Expense, error) {
+func (db *MySQLExpenseDB) GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error) {
 	// Виконання запиту до бази даних для отримання витрат користувача за його ідентифікатором
-	query := "SELECT id, amount, category, date FROM expenses WHERE user_id = ?"
-	rows, err := db.DB.Query(query, userID)
+	// (витрати з кошика не повертаються)
+	query := "SELECT id, amount, category, date, version FROM expenses WHERE user_id = ? AND deleted_at IS NULL ORDER BY id"
+	rows, err := db.DB.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +27,7 @@ func (db *MySQLExpenseDB) GetUserExpenses(userID int) ([]models.Expense, error)
 	var expenses []models.Expense
 	for rows.Next() {
 		var expense models.Expense
-		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date)
+		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -38,35 +41,197 @@ func (db *MySQLExpenseDB) GetUserExpenses(userID int) ([]models.Expense, error)
 	return expenses, nil
 }
 
-func (db *MySQLExpenseDB) AddExpense(expense models.Expense) error {
-	// Виконання запиту до бази даних для збереження витрати
-	query := "INSERT INTO expenses (amount, category, date, user_id) VALUES (?, ?, ?, ?)"
-	_, err := db.DB.Exec(query, expense.Amount, expense.Category, expense.Date, expense.UserID)
+func (db *MySQLExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	// Отримання однієї активної витрати користувача
+	query := "SELECT id, amount, category, date, user_id, version FROM expenses WHERE id = ? AND user_id = ? AND deleted_at IS NULL"
+	row := db.DB.QueryRowContext(ctx, query, expenseID, userID)
+
+	var expense models.Expense
+	err := row.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.UserID, &expense.Version)
 	if err != nil {
-		return err
+		if err == sql.ErrNoRows {
+			return models.Expense{}, ErrNotFound
+		}
+		return models.Expense{}, err
 	}
 
-	return nil
+	return expense, nil
+}
+
+func (db *MySQLExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit Audit) (int, error) {
+	// Збереження витрати разом з ревізією її створення
+	return addAudited(ctx, db.DB, expense, audit, mysqlBatchCreate, mysqlInsertRevision)
+}
+
+func (db *MySQLExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) error {
+	// Переміщення витрати в кошик замість фізичного видалення (лише якщо версія не змінилась)
+	op := models.BatchOperation{Op: models.BatchDelete, ID: expenseID, Version: version}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionDelete, audit, mysqlBatchApply, mysqlInsertRevision)
+}
+
+func (db *MySQLExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) error {
+	// Оновлення витрати (лише якщо версія не змінилась)
+	op := models.BatchOperation{Op: models.BatchUpdate, ID: expense.ID, Version: expense.Version, Expense: expense}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionUpdate, audit, mysqlBatchApply, mysqlInsertRevision)
+}
+
+func (db *MySQLExpenseDB) GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error) {
+	// Отримання витрат користувача, що знаходяться в кошику (останні видалені - першими)
+	query := "SELECT id, amount, category, date, version, deleted_at FROM expenses WHERE user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := db.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		var deletedAt time.Time
+		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.Version, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		expense.DeletedAt = &deletedAt
+		expenses = append(expenses, expense)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+func (db *MySQLExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	// Повернення витрати з кошика
+	op := models.BatchOperation{Op: models.ActionRestore, ID: expenseID}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionRestore, audit, mysqlTrashApply, mysqlInsertRevision)
+}
+
+func (db *MySQLExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	// Остаточне видалення витрати (лише з кошика)
+	op := models.BatchOperation{Op: models.ActionPurge, ID: expenseID}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionPurge, audit, mysqlTrashApply, mysqlInsertRevision)
 }
 
-func (db *MySQLExpenseDB) DeleteExpense(expenseID string) error {
-	// Виконання запиту до бази даних для видалення витрати за її ідентифікатором
-	query := "DELETE FROM expenses WHERE id = ?"
-	_, err := db.DB.Exec(query, expenseID)
+func (db *MySQLExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Остаточне видалення всіх витрат, що потрапили в кошик раніше за deletedBefore
+	query := "SELECT id, amount, category, date, user_id, version, deleted_at FROM expenses WHERE deleted_at IS NOT NULL AND deleted_at < ? FOR UPDATE"
+	return purgeTrash(ctx, db.DB, query, "DELETE FROM expenses WHERE id = ?", deletedBefore, mysqlInsertRevision)
+}
+
+// mysqlTrashApply повертає витрату з кошика (op.Op - models.ActionRestore) або остаточно видаляє її
+// (models.ActionPurge); рядок блокується до кінця транзакції
+func mysqlTrashApply(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error {
+	query := "SELECT id, amount, category, date, user_id, version, deleted_at FROM expenses WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL FOR UPDATE"
+	before, err := scanTrashed(tx.QueryRowContext(ctx, query, op.ID, userID))
 	if err != nil {
 		return err
 	}
 
+	switch op.Op {
+	case models.ActionRestore:
+		_, err = tx.ExecContext(ctx, "UPDATE expenses SET deleted_at = NULL, version = version + 1 WHERE id = ?", before.ID)
+	case models.ActionPurge:
+		_, err = tx.ExecContext(ctx, "DELETE FROM expenses WHERE id = ?", before.ID)
+	default:
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	*result = trashResult(op.Op, before)
 	return nil
 }
 
-func (db *MySQLExpenseDB) UpdateUserExpenses(expense models.Expense) error {
-	// Виконання запиту до бази даних для оновлення витрати
-	query := "UPDATE expenses SET amount = ?, category = ?, date = ? WHERE id = ?"
-	_, err := db.DB.Exec(query, expense.Amount, expense.Category, expense.Date, expense.ID)
+// scanTrashed читає витрату з кошика; відсутній рядок - ErrNotFound
+func scanTrashed(row rowScanner) (models.Expense, error) {
+	var expense models.Expense
+	var deletedAt time.Time
+	err := row.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.UserID, &expense.Version, &deletedAt)
 	if err != nil {
-		return err
+		if err == sql.ErrNoRows {
+			return models.Expense{}, ErrNotFound
+		}
+		return models.Expense{}, err
+	}
+
+	expense.DeletedAt = &deletedAt
+	return expense, nil
+}
+
+// purgeTrash - спільна для SQL-сховищ реалізація ExpenseDB.PurgeTrash: в одній транзакції вибирає
+// витрати кошика запитом selectQuery, видаляє кожну запитом deleteQuery та записує ревізію її
+// остаточного видалення
+func purgeTrash(ctx context.Context, conn *sql.DB, selectQuery, deleteQuery string, deletedBefore time.Time, insert revisionInsertFunc) (int64, error) {
+	var purged int64
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		expenses, err := queryTrashed(ctx, tx, selectQuery, deletedBefore.UTC())
+		if err != nil {
+			return err
+		}
+
+		for i := range expenses {
+			if _, err := tx.ExecContext(ctx, deleteQuery, expenses[i].ID); err != nil {
+				return err
+			}
+			if err := trashRetentionAudit(expenses[i].UserID).record(ctx, tx, insert, models.ActionPurge, &expenses[i], nil); err != nil {
+				return err
+			}
+		}
+
+		purged = int64(len(expenses))
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
+	return purged, nil
+}
+
+// queryTrashed читає всі витрати кошика, повернуті запитом; рядки закриваються до наступних запитів транзакції
+func queryTrashed(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]models.Expense, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		expense, err := scanTrashed(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+
+	return expenses, rows.Err()
+}
+
+// trashResult - стан витрати з кошика до та після відновлення чи остаточного видалення
+func trashResult(action string, before models.Expense) BatchResult {
+	if action == models.ActionPurge {
+		return BatchResult{Before: &before}
+	}
+
+	after := before
+	after.DeletedAt = nil
+	after.Version++
+	return BatchResult{Before: &before, After: &after}
+}
+
+// checkAffected повертає ErrNotFound, якщо запит не змінив жодного рядка
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
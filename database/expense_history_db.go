@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// --------------------------- Логіка роботи з історією змін витрат (MySQL) ---------------------------
+type MySQLExpenseHistoryDB struct {
+	DB *sql.DB
+}
+
+func (db *MySQLExpenseHistoryDB) GetExpenseHistory(ctx context.Context, userID, expenseID int) ([]models.ExpenseRevision, error) {
+	// Історія повертається в хронологічному порядку
+	query := `SELECT id, expense_id, user_id, actor_id, action, before_state, after_state, request_id, created_at
+		FROM expense_revisions WHERE user_id = ? AND expense_id = ? ORDER BY id`
+	rows, err := db.DB.QueryContext(ctx, query, userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.ExpenseRevision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (db *MySQLExpenseHistoryDB) GetRevision(ctx context.Context, userID, expenseID, revisionID int) (models.ExpenseRevision, error) {
+	query := `SELECT id, expense_id, user_id, actor_id, action, before_state, after_state, request_id, created_at
+		FROM expense_revisions WHERE id = ? AND user_id = ? AND expense_id = ?`
+	revision, err := scanRevision(db.DB.QueryRowContext(ctx, query, revisionID, userID, expenseID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ExpenseRevision{}, ErrNotFound
+		}
+		return models.ExpenseRevision{}, err
+	}
+
+	return revision, nil
+}
+
+// mysqlInsertRevision записує ревізію в транзакції зміни витрати
+func mysqlInsertRevision(ctx context.Context, q execer, revision models.ExpenseRevision) error {
+	query := `INSERT INTO expense_revisions
+		(expense_id, user_id, actor_id, action, before_state, after_state, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := q.ExecContext(ctx, query, revision.ExpenseID, revision.UserID, revision.ActorID, revision.Action,
+		nullableJSON(revision.Before), nullableJSON(revision.After), revision.RequestID, revision.CreatedAt.UTC())
+	return err
+}
+
+// rowScanner об'єднує *sql.Row та *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRevision(row rowScanner) (models.ExpenseRevision, error) {
+	var revision models.ExpenseRevision
+	var before, after []byte
+	err := row.Scan(&revision.ID, &revision.ExpenseID, &revision.UserID, &revision.ActorID, &revision.Action,
+		&before, &after, &revision.RequestID, &revision.CreatedAt)
+	if err != nil {
+		return revision, err
+	}
+
+	revision.Before = before
+	revision.After = after
+
+	return revision, nil
+}
+
+// nullableJSON перетворює порожній JSON на NULL
+func nullableJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
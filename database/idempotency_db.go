@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry - код помилки MySQL при порушенні унікального ключа
+const mysqlDuplicateEntry = 1062
+
+// --------------------------- Логіка роботи з ключами ідемпотентності (MySQL) ---------------------------
+type MySQLIdempotencyDB struct {
+	DB *sql.DB
+}
+
+func (db *MySQLIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	return reserveWithRetry(record, func() (models.IdempotencyRecord, bool, error) {
+		return db.reserve(ctx, record)
+	})
+}
+
+func (db *MySQLIdempotencyDB) reserve(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	// Прострочений ключ можна використати повторно
+	query := "DELETE FROM idempotency_keys WHERE user_id = ? AND idem_key = ? AND expires_at <= ?"
+	_, err := db.DB.ExecContext(ctx, query, record.UserID, record.Key, record.CreatedAt.UTC())
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	query = `INSERT INTO idempotency_keys (user_id, idem_key, fingerprint, status_code, created_at, expires_at)
+		VALUES (?, ?, ?, 0, ?, ?)`
+	_, err = db.DB.ExecContext(ctx, query, record.UserID, record.Key, record.Fingerprint, record.CreatedAt.UTC(), record.ExpiresAt.UTC())
+	if err == nil {
+		return record, true, nil
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	// Ключ уже зайнятий - повертаємо збережений запис
+	existing, err := getIdempotencyKey(ctx, db.DB, record.UserID, record.Key)
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (db *MySQLIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE idempotency_keys SET status_code = ?, response_headers = ?, response_body = ? WHERE user_id = ? AND idem_key = ?"
+	res, err := db.DB.ExecContext(ctx, query, record.StatusCode, string(headers), record.Body, record.UserID, record.Key)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (db *MySQLIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE user_id = ? AND idem_key = ?"
+	_, err := db.DB.ExecContext(ctx, query, userID, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (db *MySQLIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := "DELETE FROM idempotency_keys WHERE expires_at <= ?"
+	res, err := db.DB.ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// reserveAttempts - кількість спроб зарезервувати ключ, запис якого зникає між вставкою та читанням
+const reserveAttempts = 3
+
+// reserveWithRetry повторює резервування reserve, якщо ключ виявився зайнятим, але його запис уже видалено
+// (ReleaseIdempotencyKey або очищення прострочених ключів між вставкою та читанням). Якщо ключ так і не
+// вдалося ні зарезервувати, ні прочитати, його вважають зайнятим запитом, що ще виконується (409),
+// а не відсутнім (404).
+func reserveWithRetry(record models.IdempotencyRecord, reserve func() (models.IdempotencyRecord, bool, error)) (models.IdempotencyRecord, bool, error) {
+	for attempt := 1; ; attempt++ {
+		existing, reserved, err := reserve()
+		if !errors.Is(err, ErrNotFound) {
+			return existing, reserved, err
+		}
+
+		if attempt == reserveAttempts {
+			record.StatusCode = 0
+			record.Headers = nil
+			record.Body = nil
+			return record, false, nil
+		}
+	}
+}
+
+// getIdempotencyKey читає збережений запис ключа (запит однаковий для MySQL та SQLite)
+func getIdempotencyKey(ctx context.Context, q execer, userID int, key string) (models.IdempotencyRecord, error) {
+	query := `SELECT user_id, idem_key, fingerprint, status_code, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE user_id = ? AND idem_key = ?`
+
+	return scanIdempotencyRecord(q.QueryRowContext(ctx, query, userID, key))
+}
+
+func scanIdempotencyRecord(row rowScanner) (models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	var headers sql.NullString
+	err := row.Scan(&record.UserID, &record.Key, &record.Fingerprint, &record.StatusCode,
+		&headers, &record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.IdempotencyRecord{}, ErrNotFound
+		}
+		return models.IdempotencyRecord{}, err
+	}
+
+	if headers.Valid {
+		err = json.Unmarshal([]byte(headers.String), &record.Headers)
+		if err != nil {
+			return models.IdempotencyRecord{}, err
+		}
+	}
+
+	return record, nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+func TestReserveWithRetry(t *testing.T) {
+	record := models.IdempotencyRecord{UserID: 1, Key: "key", Fingerprint: "fingerprint"}
+	stored := models.IdempotencyRecord{UserID: 1, Key: "key", Fingerprint: "fingerprint", StatusCode: 201}
+
+	// Запис зайнятого ключа зник один раз - друга спроба повертає збережену відповідь
+	calls := 0
+	existing, reserved, err := reserveWithRetry(record, func() (models.IdempotencyRecord, bool, error) {
+		calls++
+		if calls == 1 {
+			return models.IdempotencyRecord{}, false, ErrNotFound
+		}
+		return stored, false, nil
+	})
+	if err != nil || reserved || existing.StatusCode != 201 || calls != 2 {
+		t.Errorf("Отримано %+v, %v, %v після %d спроб, очікувався збережений запис після 2", existing, reserved, err, calls)
+	}
+
+	// Запис постійно зникає - ключ вважається зайнятим запитом, що виконується, а не відсутнім
+	calls = 0
+	existing, reserved, err = reserveWithRetry(record, func() (models.IdempotencyRecord, bool, error) {
+		calls++
+		return models.IdempotencyRecord{}, false, ErrNotFound
+	})
+	if err != nil || reserved || existing.StatusCode != 0 || existing.Fingerprint != record.Fingerprint || calls != reserveAttempts {
+		t.Errorf("Отримано %+v, %v, %v після %d спроб, очікувався зайнятий ключ після %d", existing, reserved, err, calls, reserveAttempts)
+	}
+
+	// Інші помилки не повторюються
+	calls = 0
+	failure := errors.New("server error")
+	if _, _, err = reserveWithRetry(record, func() (models.IdempotencyRecord, bool, error) {
+		calls++
+		return models.IdempotencyRecord{}, false, failure
+	}); !errors.Is(err, failure) || calls != 1 {
+		t.Errorf("Отримано помилку %v після %d спроб, очікувалася %v після 1", err, calls, failure)
+	}
+}
@@ -1,11 +1,35 @@
 package database
 
-import "github.com/ChomuCake/uni-golang-labs/models"
+import (
+	"context"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
 
 // ExpenseDB визначає інтерфейс для роботи з даними витрат
 type ExpenseDB interface {
-	GetUserExpenses(userID int) ([]models.Expense, error)
-	AddExpense(expense models.Expense) error
-	DeleteExpense(expenseID string) error
-	UpdateUserExpenses(expense models.Expense) error
+	GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error)
+	GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error)
+
+	// AddExpense, DeleteExpense, UpdateUserExpenses, RestoreExpense та PurgeExpense записують ревізію історії
+	// від імені audit у тій самій транзакції, що й зміну
+	AddExpense(ctx context.Context, expense models.Expense, audit Audit) (int, error)
+	// Зміна та видалення виконуються лише для витрати користувача userID і лише якщо expense.Version/version
+	// збігається з поточною версією, інакше повертається ErrNotFound або ErrVersionConflict
+	DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) error
+	UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) error
+
+	// Пакетні операції: ApplyBatch виконує операції в одній транзакції - атомарно або з незалежним
+	// результатом для кожної - та в ній же записує ревізії успішних операцій від імені audit;
+	// послідовні створення зберігаються одним багаторядковим INSERT
+	ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error)
+
+	// Кошик: видалені витрати зберігаються до остаточного очищення
+	GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error)
+	RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) error
+	PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) error
+	// PurgeTrash остаточно видаляє витрати, що потрапили в кошик раніше за deletedBefore, і в тій самій
+	// транзакції записує для кожної ревізію models.ActionPurge від імені її власника
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
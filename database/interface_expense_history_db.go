@@ -0,0 +1,14 @@
+package database
+
+import (
+	"context"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// ExpenseHistoryDB визначає інтерфейс для читання історії змін витрат.
+// Ревізії записують лише операції ExpenseDB у тій самій транзакції, що й зміну.
+type ExpenseHistoryDB interface {
+	GetExpenseHistory(ctx context.Context, userID, expenseID int) ([]models.ExpenseRevision, error)
+	GetRevision(ctx context.Context, userID, expenseID, revisionID int) (models.ExpenseRevision, error)
+}
@@ -0,0 +1,20 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// IdempotencyDB визначає інтерфейс для зберігання ключів ідемпотентності
+type IdempotencyDB interface {
+	// ReserveIdempotencyKey резервує ключ для нового запиту. Якщо ключ уже існує і не прострочений,
+	// повертається збережений запис і false
+	ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error)
+	// CompleteIdempotencyKey зберігає відповідь на зарезервований запит
+	CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error
+	// ReleaseIdempotencyKey звільняє ключ, щоб запит можна було повторити (наприклад, після помилки сервера)
+	ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
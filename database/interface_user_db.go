@@ -1,11 +1,16 @@
 package database
 
-import "github.com/ChomuCake/uni-golang-labs/models"
+import (
+	"context"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
 
 // UserDB визначає інтерфейс для роботи з даними юзерів
 type UserDB interface {
-	AddUser(user models.User) error
-	GetUserByUsernameAndPassword(username, password string) (models.User, error)
-	GetUserByUsername(username string) (models.User, error)
-	GetUserByID(userID int) (models.User, error)
+	AddUser(ctx context.Context, user models.User) error
+	GetUserByUsernameAndPassword(ctx context.Context, username, password string) (models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	// GetUserByID повертає ErrNotFound, якщо користувача немає
+	GetUserByID(ctx context.Context, userID int) (models.User, error)
 }
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// LogObserver записує операції сховищ у журнал: збої - на рівні error (скасовані та прострочені - warn),
+// решту - на рівні debug. Ідентифікатор запиту потрапляє в запис із контексту операції.
+type LogObserver struct {
+	Logger *slog.Logger
+}
+
+// Observe реалізує Observer
+func (o LogObserver) Observe(ctx context.Context, store, method string) (context.Context, func(err error)) {
+	start := time.Now()
+
+	return ctx, func(err error) {
+		attrs := []slog.Attr{
+			slog.String("store", store),
+			slog.String("method", method),
+			slog.Float64("duration_ms", milliseconds(time.Since(start))),
+		}
+
+		level := slog.LevelDebug
+		msg := "storage operation"
+		switch {
+		case err == nil, errors.Is(err, sql.ErrNoRows), errors.Is(err, ErrNotFound), errors.Is(err, ErrVersionConflict):
+			// Відсутній запис чи конфлікт версій - очікувані результати, а не збої
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			level, msg = slog.LevelWarn, "storage operation interrupted"
+		default:
+			level, msg = slog.LevelError, "storage operation failed"
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		o.Logger.LogAttrs(ctx, level, msg, attrs...)
+	}
+}
+
+// milliseconds переводить тривалість у мілісекунди з точністю до мікросекунди
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
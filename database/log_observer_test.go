@@ -0,0 +1,50 @@
+package database_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/logging"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// errUserDB повертає помилку, задану для кожного ID
+type errUserDB struct {
+	database.UserDB
+	errs map[int]error
+}
+
+func (db *errUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	return models.User{ID: userID}, db.errs[userID]
+}
+
+func TestLogObserver(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+	users := &errUserDB{errs: map[int]error{2: sql.ErrNoRows, 3: errors.New("connection reset")}}
+	storage := (&database.Storage{Users: users}).WithObserver(database.LogObserver{Logger: logger})
+	ctx := logging.WithRequestID(t.Context(), "req-db")
+
+	// Act
+	for _, id := range []int{1, 2, 3} {
+		storage.Users.GetUserByID(ctx, id)
+	}
+
+	// Assert: успішні операції та відсутні записи пишуться лише на рівні debug
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Отримано %d записів, очікувався 1: %q", len(lines), buf.String())
+	}
+	for _, want := range []string{`"level":"ERROR"`, `"method":"GetUserByID"`, `"error":"connection reset"`, `"request_id":"req-db"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("Запис %s не містить %s", lines[0], want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// ErrForeignKey повертається сховищем у пам'яті, якщо запис посилається на неіснуючого користувача
+// (аналог порушення зовнішнього ключа в СУБД)
+var ErrForeignKey = errors.New("database: referenced user does not exist")
+
+// MemoryDB - "база даних" у пам'яті процесу для розробки та тестів. Дані втрачаються при зупинці.
+// Сховища MemoryExpenseDB, MemoryUserDB, MemoryExpenseHistoryDB та MemoryIdempotencyDB працюють
+// з одним MemoryDB так само, як SQL-сховища працюють з одним *sql.DB. Усі операції захищені м'ютексом.
+type MemoryDB struct {
+	mu sync.Mutex
+
+	users       []models.User
+	expenses    map[int]models.Expense
+	revisions   []models.ExpenseRevision
+	idempotency map[memoryIdempotencyKey]models.IdempotencyRecord
+
+	lastUserID     int
+	lastExpenseID  int
+	lastRevisionID int
+}
+
+type memoryIdempotencyKey struct {
+	userID int
+	key    string
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		expenses:    make(map[int]models.Expense),
+		idempotency: make(map[memoryIdempotencyKey]models.IdempotencyRecord),
+	}
+}
+
+// lock захоплює mu, якщо ctx ще не скасовано. Операції в пам'яті не блокуються на введенні-виведенні,
+// тому контекст перевіряється лише перед їх початком.
+func (m *MemoryDB) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	return nil
+}
+
+// userExists перевіряє наявність користувача; викликається з заблокованим mu
+func (m *MemoryDB) userExists(userID int) bool {
+	for _, user := range m.users {
+		if user.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// copyExpense повертає копію витрати, що не ділить DeletedAt зі збереженою
+func copyExpense(expense models.Expense) models.Expense {
+	if expense.DeletedAt != nil {
+		deletedAt := *expense.DeletedAt
+		expense.DeletedAt = &deletedAt
+	}
+	return expense
+}
+
+// utcNow повертає поточний час у UTC, як його повертають SQL-сховища
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,347 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// --------------------------- Логіка роботи з даними для витрат (у пам'яті) ---------------------------
+// Повертає ті самі поля та помилки, що й SQL-сховища: списки впорядковані за ID, UserID заповнюється
+// лише в GetExpenseByID, відсутній користувач - ErrForeignKey
+type MemoryExpenseDB struct {
+	DB *MemoryDB
+}
+
+func (db *MemoryExpenseDB) GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.DB.mu.Unlock()
+
+	var expenses []models.Expense
+	for _, expense := range db.DB.expenses {
+		if expense.UserID == userID && expense.DeletedAt == nil {
+			expense.UserID = 0
+			expenses = append(expenses, expense)
+		}
+	}
+
+	sort.Slice(expenses, func(i, j int) bool { return expenses[i].ID < expenses[j].ID })
+
+	return expenses, nil
+}
+
+func (db *MemoryExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return models.Expense{}, err
+	}
+	defer db.DB.mu.Unlock()
+
+	expense, ok := db.DB.expenses[expenseID]
+	if !ok || expense.UserID != userID || expense.DeletedAt != nil {
+		return models.Expense{}, ErrNotFound
+	}
+
+	return expense, nil
+}
+
+func (db *MemoryExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit Audit) (int, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer db.DB.mu.Unlock()
+
+	id, err := insertMemoryExpense(db.DB, db.DB.expenses, expense)
+	if err != nil {
+		return 0, err
+	}
+
+	// Без ревізії витрата не зберігається (аналог відкату транзакції)
+	created := db.DB.expenses[id]
+	if err := recordMemoryRevision(db.DB, audit, models.ActionCreate, nil, &created); err != nil {
+		delete(db.DB.expenses, id)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (db *MemoryExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	before, err := findVersioned(db.DB.expenses, userID, expenseID, version)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	deletedAt := utcNow()
+	after.DeletedAt = &deletedAt
+	after.Version++
+
+	if err := recordMemoryRevision(db.DB, audit, models.ActionDelete, &before, &after); err != nil {
+		return err
+	}
+	db.DB.expenses[after.ID] = after
+
+	return nil
+}
+
+func (db *MemoryExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	before, err := findVersioned(db.DB.expenses, userID, expense.ID, expense.Version)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Amount = expense.Amount
+	after.Category = expense.Category
+	after.Date = expense.Date.UTC()
+	after.Version++
+
+	if err := recordMemoryRevision(db.DB, audit, models.ActionUpdate, &before, &after); err != nil {
+		return err
+	}
+	db.DB.expenses[after.ID] = after
+
+	return nil
+}
+
+func (db *MemoryExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.DB.mu.Unlock()
+
+	staged, lastID := db.stage()
+
+	results := make([]BatchResult, len(ops))
+	failed := false
+	for i := 0; i < len(ops) && !(atomic && failed); i++ {
+		if err := applyMemoryBatchOp(db.DB, staged, userID, ops[i], &results[i]); err != nil {
+			results[i] = BatchResult{Err: err}
+			failed = true
+		}
+	}
+
+	// В атомарному режимі помилка однієї операції скасовує всі інші
+	if atomic && failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BatchResult{Err: ErrBatchAborted}
+			}
+		}
+		db.DB.lastExpenseID = lastID
+		return results, nil
+	}
+
+	if err := recordMemoryBatch(db.DB, audit, ops, results); err != nil {
+		db.DB.lastExpenseID = lastID
+		return nil, err
+	}
+
+	db.DB.expenses = staged
+	return results, nil
+}
+
+func (db *MemoryExpenseDB) GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.DB.mu.Unlock()
+
+	var expenses []models.Expense
+	for _, expense := range db.DB.expenses {
+		if expense.UserID == userID && expense.DeletedAt != nil {
+			expense = copyExpense(expense)
+			expense.UserID = 0
+			expenses = append(expenses, expense)
+		}
+	}
+
+	// Останні видалені - першими
+	sort.Slice(expenses, func(i, j int) bool { return expenses[i].DeletedAt.After(*expenses[j].DeletedAt) })
+
+	return expenses, nil
+}
+
+func (db *MemoryExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	before, ok := findInMemoryTrash(db.DB.expenses, userID, expenseID)
+	if !ok {
+		return ErrNotFound
+	}
+
+	result := trashResult(models.ActionRestore, before)
+	if err := recordMemoryRevision(db.DB, audit, models.ActionRestore, result.Before, result.After); err != nil {
+		return err
+	}
+	db.DB.expenses[before.ID] = *result.After
+
+	return nil
+}
+
+func (db *MemoryExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	before, ok := findInMemoryTrash(db.DB.expenses, userID, expenseID)
+	if !ok {
+		return ErrNotFound
+	}
+
+	if err := recordMemoryRevision(db.DB, audit, models.ActionPurge, &before, nil); err != nil {
+		return err
+	}
+	delete(db.DB.expenses, before.ID)
+
+	return nil
+}
+
+func (db *MemoryExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer db.DB.mu.Unlock()
+
+	// Ревізії всіх витрат формуються до видалення, тож помилка нічого не змінює (аналог транзакції)
+	var purged []models.Expense
+	var revisions []models.ExpenseRevision
+	for _, expense := range db.DB.expenses {
+		if expense.DeletedAt == nil || !expense.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+
+		revision, err := trashRetentionAudit(expense.UserID).revision(models.ActionPurge, &expense, nil)
+		if err != nil {
+			return 0, err
+		}
+		if !db.DB.userExists(revision.UserID) {
+			return 0, ErrForeignKey
+		}
+		purged = append(purged, expense)
+		revisions = append(revisions, revision)
+	}
+
+	for i, expense := range purged {
+		if err := addMemoryRevision(db.DB, revisions[i]); err != nil {
+			return 0, err
+		}
+		delete(db.DB.expenses, expense.ID)
+	}
+
+	return int64(len(purged)), nil
+}
+
+// stage повертає копію витрат для зміни "в транзакції" та поточний лічильник ID для відкату
+func (db *MemoryExpenseDB) stage() (map[int]models.Expense, int) {
+	staged := make(map[int]models.Expense, len(db.DB.expenses))
+	for id, expense := range db.DB.expenses {
+		staged[id] = expense
+	}
+	return staged, db.DB.lastExpenseID
+}
+
+// insertMemoryExpense додає витрату в expenses та повертає її ID; викликається з заблокованим mu
+func insertMemoryExpense(m *MemoryDB, expenses map[int]models.Expense, expense models.Expense) (int, error) {
+	if !m.userExists(expense.UserID) {
+		return 0, ErrForeignKey
+	}
+
+	m.lastExpenseID++
+	expense.ID = m.lastExpenseID
+	expense.Date = expense.Date.UTC()
+	expense.RawDate = ""
+	expense.Version = 1
+	expense.DeletedAt = nil
+	expenses[expense.ID] = expense
+
+	return expense.ID, nil
+}
+
+// findVersioned знаходить активну витрату користувача та перевіряє її версію
+func findVersioned(expenses map[int]models.Expense, userID, expenseID, version int) (models.Expense, error) {
+	expense, ok := expenses[expenseID]
+	if !ok || expense.UserID != userID || expense.DeletedAt != nil {
+		return models.Expense{}, ErrNotFound
+	}
+	if expense.Version != version {
+		return models.Expense{}, ErrVersionConflict
+	}
+
+	return expense, nil
+}
+
+func findInMemoryTrash(expenses map[int]models.Expense, userID, expenseID int) (models.Expense, bool) {
+	expense, ok := expenses[expenseID]
+	if !ok || expense.UserID != userID || expense.DeletedAt == nil {
+		return models.Expense{}, false
+	}
+
+	return expense, true
+}
+
+func applyMemoryBatchOp(m *MemoryDB, expenses map[int]models.Expense, userID int, op models.BatchOperation, result *BatchResult) error {
+	if op.Op == models.BatchCreate {
+		created := op.Expense
+		created.UserID = userID
+
+		id, err := insertMemoryExpense(m, expenses, created)
+		if err != nil {
+			return err
+		}
+
+		created.ID = id
+		created.Version = 1
+		created.RawDate = ""
+		*result = BatchResult{After: &created}
+		return nil
+	}
+
+	before, ok := expenses[op.ID]
+	if !ok || before.UserID != userID || before.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if before.Version != op.Version {
+		return ErrVersionConflict
+	}
+
+	after := before
+	after.Version++
+
+	switch op.Op {
+	case models.BatchUpdate:
+		after.Amount = op.Expense.Amount
+		after.Category = op.Expense.Category
+		after.Date = op.Expense.Date
+	case models.BatchDelete:
+		deletedAt := utcNow()
+		after.DeletedAt = &deletedAt
+	default:
+		return ErrNotFound
+	}
+
+	stored := copyExpense(after)
+	stored.Date = stored.Date.UTC()
+	expenses[after.ID] = stored
+
+	*result = BatchResult{Before: &before, After: &after}
+	return nil
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// --------------------------- Логіка роботи з історією змін витрат (у пам'яті) ---------------------------
+type MemoryExpenseHistoryDB struct {
+	DB *MemoryDB
+}
+
+func (db *MemoryExpenseHistoryDB) GetExpenseHistory(ctx context.Context, userID, expenseID int) ([]models.ExpenseRevision, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer db.DB.mu.Unlock()
+
+	// Записи додаються з зростаючими ID, тож історія вже в хронологічному порядку
+	var revisions []models.ExpenseRevision
+	for _, revision := range db.DB.revisions {
+		if revision.UserID == userID && revision.ExpenseID == expenseID {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	return revisions, nil
+}
+
+func (db *MemoryExpenseHistoryDB) GetRevision(ctx context.Context, userID, expenseID, revisionID int) (models.ExpenseRevision, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return models.ExpenseRevision{}, err
+	}
+	defer db.DB.mu.Unlock()
+
+	for _, revision := range db.DB.revisions {
+		if revision.ID == revisionID && revision.UserID == userID && revision.ExpenseID == expenseID {
+			return revision, nil
+		}
+	}
+
+	return models.ExpenseRevision{}, ErrNotFound
+}
+
+// addMemoryRevision зберігає ревізію; викликається з заблокованим mu
+func addMemoryRevision(m *MemoryDB, revision models.ExpenseRevision) error {
+	if !m.userExists(revision.UserID) || !m.userExists(revision.ActorID) {
+		return ErrForeignKey
+	}
+
+	m.lastRevisionID++
+	revision.ID = m.lastRevisionID
+	revision.Before = copyJSON(revision.Before)
+	revision.After = copyJSON(revision.After)
+	revision.CreatedAt = revision.CreatedAt.UTC()
+	m.revisions = append(m.revisions, revision)
+
+	return nil
+}
+
+// recordMemoryRevision записує ревізію зміни витрати від імені audit; викликається з заблокованим mu.
+// Сховище витрат викликає її до збереження зміни, щоб помилка запису історії не залишила зміну без ревізії.
+func recordMemoryRevision(m *MemoryDB, audit Audit, action string, before, after *models.Expense) error {
+	revision, err := audit.revision(action, before, after)
+	if err != nil {
+		return err
+	}
+
+	return addMemoryRevision(m, revision)
+}
+
+// recordMemoryBatch записує ревізії успішних операцій пакета: спочатку формуються та перевіряються всі,
+// тож помилка не залишає в історії частини пакета
+func recordMemoryBatch(m *MemoryDB, audit Audit, ops []models.BatchOperation, results []BatchResult) error {
+	var revisions []models.ExpenseRevision
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		revision, err := audit.revision(batchActions[ops[i].Op], result.Before, result.After)
+		if err != nil {
+			return err
+		}
+		if !m.userExists(revision.UserID) || !m.userExists(revision.ActorID) {
+			return ErrForeignKey
+		}
+		revisions = append(revisions, revision)
+	}
+
+	for _, revision := range revisions {
+		if err := addMemoryRevision(m, revision); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyJSON копіює знімок, щоб зміни у викликача не впливали на збережену історію; порожній стає nil (NULL)
+func copyJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	return append(json.RawMessage(nil), raw...)
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// --------------------------- Логіка роботи з ключами ідемпотентності (у пам'яті) ---------------------------
+type MemoryIdempotencyDB struct {
+	DB *MemoryDB
+}
+
+func (db *MemoryIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+	defer db.DB.mu.Unlock()
+
+	if !db.DB.userExists(record.UserID) {
+		return models.IdempotencyRecord{}, false, ErrForeignKey
+	}
+
+	key := memoryIdempotencyKey{userID: record.UserID, key: record.Key}
+
+	// Прострочений ключ можна використати повторно
+	if existing, ok := db.DB.idempotency[key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return existing, false, nil
+	}
+
+	record.StatusCode = 0
+	record.Headers = nil
+	record.Body = nil
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.ExpiresAt = record.ExpiresAt.UTC()
+	db.DB.idempotency[key] = record
+
+	return record, true, nil
+}
+
+func (db *MemoryIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	key := memoryIdempotencyKey{userID: record.UserID, key: record.Key}
+	stored, ok := db.DB.idempotency[key]
+	if !ok {
+		return ErrNotFound
+	}
+
+	stored.StatusCode = record.StatusCode
+	stored.Headers = make(map[string]string, len(record.Headers))
+	for name, value := range record.Headers {
+		stored.Headers[name] = value
+	}
+	stored.Body = append([]byte(nil), record.Body...)
+	db.DB.idempotency[key] = stored
+
+	return nil
+}
+
+func (db *MemoryIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	delete(db.DB.idempotency, memoryIdempotencyKey{userID: userID, key: key})
+	return nil
+}
+
+func (db *MemoryIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer db.DB.mu.Unlock()
+
+	var purged int64
+	for key, record := range db.DB.idempotency {
+		if !record.ExpiresAt.After(now) {
+			delete(db.DB.idempotency, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
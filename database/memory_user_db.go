@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// --------------------------- Логіка роботи з даними для юзера (у пам'яті) ---------------------------
+type MemoryUserDB struct {
+	DB *MemoryDB
+}
+
+func (db *MemoryUserDB) AddUser(ctx context.Context, user models.User) error {
+	if err := db.DB.lock(ctx); err != nil {
+		return err
+	}
+	defer db.DB.mu.Unlock()
+
+	// ID призначається сховищем, як AUTO_INCREMENT
+	db.DB.lastUserID++
+	user.ID = db.DB.lastUserID
+	db.DB.users = append(db.DB.users, user)
+
+	return nil
+}
+
+func (db *MemoryUserDB) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (models.User, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return models.User{}, err
+	}
+	defer db.DB.mu.Unlock()
+
+	for _, user := range db.DB.users {
+		if user.Username == username && user.Password == password {
+			return models.User{ID: user.ID, Username: user.Username}, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (db *MemoryUserDB) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return models.User{}, err
+	}
+	defer db.DB.mu.Unlock()
+
+	for _, user := range db.DB.users {
+		if user.Username == username {
+			return models.User{ID: user.ID, Username: user.Username}, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (db *MemoryUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	if err := db.DB.lock(ctx); err != nil {
+		return models.User{}, err
+	}
+	defer db.DB.mu.Unlock()
+
+	for _, user := range db.DB.users {
+		if user.ID == userID {
+			return models.User{ID: user.ID, Username: user.Username}, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// Назви сховищ, що передаються Observer
+const (
+	StoreExpenses    = "expenses"
+	StoreUsers       = "users"
+	StoreHistory     = "history"
+	StoreIdempotency = "idempotency"
+)
+
+// Observer отримує сповіщення про операції сховищ (метрики, журналювання, трасування)
+type Observer interface {
+	// Observe викликається перед операцією method сховища store. Повернутий контекст передається
+	// операції, а повернута функція викликається після неї з помилкою операції (nil - успіх).
+	Observe(ctx context.Context, store, method string) (context.Context, func(err error))
+}
+
+// WithObserver повертає копію storage, кожна операція сховищ якої повідомляється observer
+func (s *Storage) WithObserver(observer Observer) *Storage {
+	observed := *s
+	observed.Expenses = &observedExpenseDB{next: s.Expenses, observer: observer}
+	observed.Users = &observedUserDB{next: s.Users, observer: observer}
+	observed.History = &observedExpenseHistoryDB{next: s.History, observer: observer}
+	observed.Idempotency = &observedIdempotencyDB{next: s.Idempotency, observer: observer}
+	return &observed
+}
+
+// --------------------------- Спостереження операцій з витратами ---------------------------
+type observedExpenseDB struct {
+	next     ExpenseDB
+	observer Observer
+}
+
+func (db *observedExpenseDB) GetUserExpenses(ctx context.Context, userID int) (_ []models.Expense, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "GetUserExpenses")
+	defer func() { done(err) }()
+	return db.next.GetUserExpenses(ctx, userID)
+}
+
+func (db *observedExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (_ models.Expense, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "GetExpenseByID")
+	defer func() { done(err) }()
+	return db.next.GetExpenseByID(ctx, userID, expenseID)
+}
+
+func (db *observedExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit Audit) (_ int, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "AddExpense")
+	defer func() { done(err) }()
+	return db.next.AddExpense(ctx, expense, audit)
+}
+
+func (db *observedExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "DeleteExpense")
+	defer func() { done(err) }()
+	return db.next.DeleteExpense(ctx, userID, expenseID, version, audit)
+}
+
+func (db *observedExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "UpdateUserExpenses")
+	defer func() { done(err) }()
+	return db.next.UpdateUserExpenses(ctx, userID, expense, audit)
+}
+
+func (db *observedExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) (_ []BatchResult, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "ApplyBatch")
+	defer func() { done(err) }()
+	return db.next.ApplyBatch(ctx, userID, ops, atomic, audit)
+}
+
+func (db *observedExpenseDB) GetUserTrash(ctx context.Context, userID int) (_ []models.Expense, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "GetUserTrash")
+	defer func() { done(err) }()
+	return db.next.GetUserTrash(ctx, userID)
+}
+
+func (db *observedExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "RestoreExpense")
+	defer func() { done(err) }()
+	return db.next.RestoreExpense(ctx, userID, expenseID, audit)
+}
+
+func (db *observedExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "PurgeExpense")
+	defer func() { done(err) }()
+	return db.next.PurgeExpense(ctx, userID, expenseID, audit)
+}
+
+func (db *observedExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreExpenses, "PurgeTrash")
+	defer func() { done(err) }()
+	return db.next.PurgeTrash(ctx, deletedBefore)
+}
+
+// --------------------------- Спостереження операцій з юзерами ---------------------------
+type observedUserDB struct {
+	next     UserDB
+	observer Observer
+}
+
+func (db *observedUserDB) AddUser(ctx context.Context, user models.User) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreUsers, "AddUser")
+	defer func() { done(err) }()
+	return db.next.AddUser(ctx, user)
+}
+
+func (db *observedUserDB) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (_ models.User, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreUsers, "GetUserByUsernameAndPassword")
+	defer func() { done(err) }()
+	return db.next.GetUserByUsernameAndPassword(ctx, username, password)
+}
+
+func (db *observedUserDB) GetUserByUsername(ctx context.Context, username string) (_ models.User, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreUsers, "GetUserByUsername")
+	defer func() { done(err) }()
+	return db.next.GetUserByUsername(ctx, username)
+}
+
+func (db *observedUserDB) GetUserByID(ctx context.Context, userID int) (_ models.User, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreUsers, "GetUserByID")
+	defer func() { done(err) }()
+	return db.next.GetUserByID(ctx, userID)
+}
+
+// --------------------------- Спостереження операцій з історією змін ---------------------------
+type observedExpenseHistoryDB struct {
+	next     ExpenseHistoryDB
+	observer Observer
+}
+
+func (db *observedExpenseHistoryDB) GetExpenseHistory(ctx context.Context, userID, expenseID int) (_ []models.ExpenseRevision, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreHistory, "GetExpenseHistory")
+	defer func() { done(err) }()
+	return db.next.GetExpenseHistory(ctx, userID, expenseID)
+}
+
+func (db *observedExpenseHistoryDB) GetRevision(ctx context.Context, userID, expenseID, revisionID int) (_ models.ExpenseRevision, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreHistory, "GetRevision")
+	defer func() { done(err) }()
+	return db.next.GetRevision(ctx, userID, expenseID, revisionID)
+}
+
+// --------------------------- Спостереження операцій з ключами ідемпотентності ---------------------------
+type observedIdempotencyDB struct {
+	next     IdempotencyDB
+	observer Observer
+}
+
+func (db *observedIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (_ models.IdempotencyRecord, _ bool, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreIdempotency, "ReserveIdempotencyKey")
+	defer func() { done(err) }()
+	return db.next.ReserveIdempotencyKey(ctx, record)
+}
+
+func (db *observedIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreIdempotency, "CompleteIdempotencyKey")
+	defer func() { done(err) }()
+	return db.next.CompleteIdempotencyKey(ctx, record)
+}
+
+func (db *observedIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) (err error) {
+	ctx, done := db.observer.Observe(ctx, StoreIdempotency, "ReleaseIdempotencyKey")
+	defer func() { done(err) }()
+	return db.next.ReleaseIdempotencyKey(ctx, userID, key)
+}
+
+func (db *observedIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (_ int64, err error) {
+	ctx, done := db.observer.Observe(ctx, StoreIdempotency, "PurgeExpiredIdempotencyKeys")
+	defer func() { done(err) }()
+	return db.next.PurgeExpiredIdempotencyKeys(ctx, now)
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// --------------------------- Логіка роботи з даними для витрат (PostgreSQL) ---------------------------
+// Стовпці часу мають тип TIMESTAMP (без часового поясу), тому всі мітки часу записуються в UTC
+type PostgresExpenseDB struct {
+	DB *sql.DB
+}
+
+func (db *PostgresExpenseDB) GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error) {
+	// Витрати з кошика не повертаються
+	query := "SELECT id, amount, category, date, version FROM expenses WHERE user_id = $1 AND deleted_at IS NULL ORDER BY id"
+	rows, err := db.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.Version)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+func (db *PostgresExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	query := "SELECT id, amount, category, date, user_id, version FROM expenses WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL"
+	row := db.DB.QueryRowContext(ctx, query, expenseID, userID)
+
+	var expense models.Expense
+	err := row.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.UserID, &expense.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Expense{}, ErrNotFound
+		}
+		return models.Expense{}, err
+	}
+
+	return expense, nil
+}
+
+func (db *PostgresExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit Audit) (int, error) {
+	return addAudited(ctx, db.DB, expense, audit, postgresBatchCreate, postgresInsertRevision)
+}
+
+func (db *PostgresExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) error {
+	// Переміщення витрати в кошик (лише якщо версія не змінилась)
+	op := models.BatchOperation{Op: models.BatchDelete, ID: expenseID, Version: version}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionDelete, audit, postgresBatchApply, postgresInsertRevision)
+}
+
+func (db *PostgresExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) error {
+	op := models.BatchOperation{Op: models.BatchUpdate, ID: expense.ID, Version: expense.Version, Expense: expense}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionUpdate, audit, postgresBatchApply, postgresInsertRevision)
+}
+
+func (db *PostgresExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error) {
+	return applyBatch(ctx, db.DB, userID, ops, atomic, audit, postgresBatchCreate, postgresBatchApply, postgresInsertRevision)
+}
+
+func (db *PostgresExpenseDB) GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error) {
+	// Останні видалені - першими
+	query := "SELECT id, amount, category, date, version, deleted_at FROM expenses WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := db.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		var deletedAt time.Time
+		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.Version, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		expense.DeletedAt = &deletedAt
+		expenses = append(expenses, expense)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+func (db *PostgresExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	op := models.BatchOperation{Op: models.ActionRestore, ID: expenseID}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionRestore, audit, postgresTrashApply, postgresInsertRevision)
+}
+
+func (db *PostgresExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	op := models.BatchOperation{Op: models.ActionPurge, ID: expenseID}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionPurge, audit, postgresTrashApply, postgresInsertRevision)
+}
+
+func (db *PostgresExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := "SELECT id, amount, category, date, user_id, version, deleted_at FROM expenses WHERE deleted_at IS NOT NULL AND deleted_at < $1 FOR UPDATE"
+	return purgeTrash(ctx, db.DB, query, "DELETE FROM expenses WHERE id = $1", deletedBefore, postgresInsertRevision)
+}
+
+// postgresTrashApply повертає витрату з кошика або остаточно видаляє її (див. mysqlTrashApply);
+// рядок блокується до кінця транзакції
+func postgresTrashApply(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error {
+	query := "SELECT id, amount, category, date, user_id, version, deleted_at FROM expenses WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL FOR UPDATE"
+	before, err := scanTrashed(tx.QueryRowContext(ctx, query, op.ID, userID))
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case models.ActionRestore:
+		_, err = tx.ExecContext(ctx, "UPDATE expenses SET deleted_at = NULL, version = version + 1 WHERE id = $1", before.ID)
+	case models.ActionPurge:
+		_, err = tx.ExecContext(ctx, "DELETE FROM expenses WHERE id = $1", before.ID)
+	default:
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	*result = trashResult(op.Op, before)
+	return nil
+}
+
+func postgresBatchCreate(ctx context.Context, tx *sql.Tx, userID int, ops []models.BatchOperation, results []BatchResult) error {
+	return batchCreate(ctx, tx, userID, ops, results, postgresInsertExpenses)
+}
+
+func postgresBatchApply(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error {
+	// Для зміни та видалення рядок блокується до кінця транзакції
+	query := "SELECT id, amount, category, date, user_id, version FROM expenses WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE"
+	var before models.Expense
+	err := tx.QueryRowContext(ctx, query, op.ID, userID).Scan(&before.ID, &before.Amount, &before.Category, &before.Date, &before.UserID, &before.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if before.Version != op.Version {
+		return ErrVersionConflict
+	}
+
+	after := before
+	after.Version++
+
+	switch op.Op {
+	case models.BatchUpdate:
+		after.Amount = op.Expense.Amount
+		after.Category = op.Expense.Category
+		after.Date = op.Expense.Date
+
+		query = "UPDATE expenses SET amount = $1, category = $2, date = $3, version = version + 1 WHERE id = $4"
+		_, err = tx.ExecContext(ctx, query, after.Amount, after.Category, after.Date.UTC(), after.ID)
+	case models.BatchDelete:
+		deletedAt := time.Now().UTC()
+		after.DeletedAt = &deletedAt
+
+		query = "UPDATE expenses SET deleted_at = $1, version = version + 1 WHERE id = $2"
+		_, err = tx.ExecContext(ctx, query, deletedAt, after.ID)
+	default:
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	*result = BatchResult{Before: &before, After: &after}
+	return nil
+}
+
+// postgresInsertExpenses зберігає витрати одним багаторядковим INSERT та повертає їхні ID.
+// Значення послідовності призначаються рядкам VALUES по черзі, тож після сортування ID
+// відповідають порядку витрат.
+func postgresInsertExpenses(ctx context.Context, q execer, expenses []models.Expense) ([]int, error) {
+	values := make([]string, len(expenses))
+	args := make([]interface{}, 0, 4*len(expenses))
+	for i, expense := range expenses {
+		n := 4 * i
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+		args = append(args, expense.Amount, expense.Category, expense.Date.UTC(), expense.UserID)
+	}
+
+	query := "WITH inserted AS (INSERT INTO expenses (amount, category, date, user_id) VALUES " +
+		strings.Join(values, ", ") + " RETURNING id) SELECT id FROM inserted ORDER BY id"
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, len(expenses))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
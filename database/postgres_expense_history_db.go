@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// --------------------------- Логіка роботи з історією змін витрат (PostgreSQL) ---------------------------
+type PostgresExpenseHistoryDB struct {
+	DB *sql.DB
+}
+
+// postgresInsertRevision записує ревізію в транзакції зміни витрати
+func postgresInsertRevision(ctx context.Context, q execer, revision models.ExpenseRevision) error {
+	query := `INSERT INTO expense_revisions
+		(expense_id, user_id, actor_id, action, before_state, after_state, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := q.ExecContext(ctx, query, revision.ExpenseID, revision.UserID, revision.ActorID, revision.Action,
+		nullableJSON(revision.Before), nullableJSON(revision.After), revision.RequestID, revision.CreatedAt.UTC())
+	return err
+}
+
+func (db *PostgresExpenseHistoryDB) GetExpenseHistory(ctx context.Context, userID, expenseID int) ([]models.ExpenseRevision, error) {
+	// Історія повертається в хронологічному порядку
+	query := `SELECT id, expense_id, user_id, actor_id, action, before_state, after_state, request_id, created_at
+		FROM expense_revisions WHERE user_id = $1 AND expense_id = $2 ORDER BY id`
+	rows, err := db.DB.QueryContext(ctx, query, userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.ExpenseRevision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (db *PostgresExpenseHistoryDB) GetRevision(ctx context.Context, userID, expenseID, revisionID int) (models.ExpenseRevision, error) {
+	query := `SELECT id, expense_id, user_id, actor_id, action, before_state, after_state, request_id, created_at
+		FROM expense_revisions WHERE id = $1 AND user_id = $2 AND expense_id = $3`
+	revision, err := scanRevision(db.DB.QueryRowContext(ctx, query, revisionID, userID, expenseID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ExpenseRevision{}, ErrNotFound
+		}
+		return models.ExpenseRevision{}, err
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// --------------------------- Логіка роботи з ключами ідемпотентності (PostgreSQL) ---------------------------
+type PostgresIdempotencyDB struct {
+	DB *sql.DB
+}
+
+func (db *PostgresIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	return reserveWithRetry(record, func() (models.IdempotencyRecord, bool, error) {
+		return db.reserve(ctx, record)
+	})
+}
+
+func (db *PostgresIdempotencyDB) reserve(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	// Прострочений ключ можна використати повторно
+	query := "DELETE FROM idempotency_keys WHERE user_id = $1 AND idem_key = $2 AND expires_at <= $3"
+	_, err := db.DB.ExecContext(ctx, query, record.UserID, record.Key, record.CreatedAt.UTC())
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	query = `INSERT INTO idempotency_keys (user_id, idem_key, fingerprint, status_code, created_at, expires_at)
+		VALUES ($1, $2, $3, 0, $4, $5) ON CONFLICT (user_id, idem_key) DO NOTHING`
+	res, err := db.DB.ExecContext(ctx, query, record.UserID, record.Key, record.Fingerprint, record.CreatedAt.UTC(), record.ExpiresAt.UTC())
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	if checkAffected(res) == nil {
+		return record, true, nil
+	}
+
+	// Ключ уже зайнятий - повертаємо збережений запис
+	query = `SELECT user_id, idem_key, fingerprint, status_code, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE user_id = $1 AND idem_key = $2`
+	existing, err := scanIdempotencyRecord(db.DB.QueryRowContext(ctx, query, record.UserID, record.Key))
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (db *PostgresIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE idempotency_keys SET status_code = $1, response_headers = $2, response_body = $3 WHERE user_id = $4 AND idem_key = $5"
+	res, err := db.DB.ExecContext(ctx, query, record.StatusCode, string(headers), record.Body, record.UserID, record.Key)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (db *PostgresIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE user_id = $1 AND idem_key = $2"
+	_, err := db.DB.ExecContext(ctx, query, userID, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (db *PostgresIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := "DELETE FROM idempotency_keys WHERE expires_at <= $1"
+	res, err := db.DB.ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// --------------------------- Логіка роботи з даними для юзера (PostgreSQL) ---------------------------
+type PostgresUserDB struct {
+	DB *sql.DB
+}
+
+func (db *PostgresUserDB) AddUser(ctx context.Context, user models.User) error {
+	_, err := db.DB.ExecContext(ctx, "INSERT INTO users(username, password) VALUES($1, $2)", user.Username, user.Password)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (db *PostgresUserDB) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (models.User, error) {
+	var user models.User
+	err := db.DB.QueryRowContext(ctx, "SELECT id, username FROM users WHERE username = $1 AND password = $2", username, password).Scan(&user.ID, &user.Username)
+	if err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+func (db *PostgresUserDB) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	err := db.DB.QueryRowContext(ctx, "SELECT id, username FROM users WHERE username = $1", username).Scan(&user.ID, &user.Username)
+	if err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+func (db *PostgresUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	var user models.User
+	err := db.DB.QueryRowContext(ctx, "SELECT id, username FROM users WHERE id = $1", userID).Scan(&user.ID, &user.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.User{}, ErrNotFound
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
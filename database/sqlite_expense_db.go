@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "modernc.org/sqlite"
+)
+
+// --------------------------- Логіка роботи з даними для витрат (SQLite) ---------------------------
+// SQLite зберігає час як текст, тому всі мітки часу записуються в UTC - тоді їх можна порівнювати в запитах
+type SQLiteExpenseDB struct {
+	DB *sql.DB
+}
+
+func (db *SQLiteExpenseDB) GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error) {
+	// Витрати з кошика не повертаються
+	query := "SELECT id, amount, category, date, version FROM expenses WHERE user_id = ? AND deleted_at IS NULL ORDER BY id"
+	rows, err := db.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.Version)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+func (db *SQLiteExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	query := "SELECT id, amount, category, date, user_id, version FROM expenses WHERE id = ? AND user_id = ? AND deleted_at IS NULL"
+	row := db.DB.QueryRowContext(ctx, query, expenseID, userID)
+
+	var expense models.Expense
+	err := row.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.UserID, &expense.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Expense{}, ErrNotFound
+		}
+		return models.Expense{}, err
+	}
+
+	return expense, nil
+}
+
+func (db *SQLiteExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit Audit) (int, error) {
+	return addAudited(ctx, db.DB, expense, audit, sqliteBatchCreate, sqliteInsertRevision)
+}
+
+func (db *SQLiteExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) error {
+	// Переміщення витрати в кошик (лише якщо версія не змінилась)
+	op := models.BatchOperation{Op: models.BatchDelete, ID: expenseID, Version: version}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionDelete, audit, sqliteBatchApply, sqliteInsertRevision)
+}
+
+func (db *SQLiteExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) error {
+	op := models.BatchOperation{Op: models.BatchUpdate, ID: expense.ID, Version: expense.Version, Expense: expense}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionUpdate, audit, sqliteBatchApply, sqliteInsertRevision)
+}
+
+func (db *SQLiteExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error) {
+	return applyBatch(ctx, db.DB, userID, ops, atomic, audit, sqliteBatchCreate, sqliteBatchApply, sqliteInsertRevision)
+}
+
+func (db *SQLiteExpenseDB) GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error) {
+	// Останні видалені - першими
+	query := "SELECT id, amount, category, date, version, deleted_at FROM expenses WHERE user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := db.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		var deletedAt time.Time
+		err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Date, &expense.Version, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		expense.DeletedAt = &deletedAt
+		expenses = append(expenses, expense)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+func (db *SQLiteExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	op := models.BatchOperation{Op: models.ActionRestore, ID: expenseID}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionRestore, audit, sqliteTrashApply, sqliteInsertRevision)
+}
+
+func (db *SQLiteExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	op := models.BatchOperation{Op: models.ActionPurge, ID: expenseID}
+	return applyAudited(ctx, db.DB, userID, op, models.ActionPurge, audit, sqliteTrashApply, sqliteInsertRevision)
+}
+
+func (db *SQLiteExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := "SELECT id, amount, category, date, user_id, version, deleted_at FROM expenses WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+	return purgeTrash(ctx, db.DB, query, "DELETE FROM expenses WHERE id = ?", deletedBefore, sqliteInsertRevision)
+}
+
+// sqliteTrashApply повертає витрату з кошика або остаточно видаляє її (див. mysqlTrashApply)
+func sqliteTrashApply(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error {
+	query := "SELECT id, amount, category, date, user_id, version, deleted_at FROM expenses WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL"
+	before, err := scanTrashed(tx.QueryRowContext(ctx, query, op.ID, userID))
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case models.ActionRestore:
+		_, err = tx.ExecContext(ctx, "UPDATE expenses SET deleted_at = NULL, version = version + 1 WHERE id = ?", before.ID)
+	case models.ActionPurge:
+		_, err = tx.ExecContext(ctx, "DELETE FROM expenses WHERE id = ?", before.ID)
+	default:
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	*result = trashResult(op.Op, before)
+	return nil
+}
+
+func sqliteBatchCreate(ctx context.Context, tx *sql.Tx, userID int, ops []models.BatchOperation, results []BatchResult) error {
+	return batchCreate(ctx, tx, userID, ops, results, sqliteInsertExpenses)
+}
+
+// sqliteBatchApply не потребує блокування рядків: транзакції SQLite відкриваються з _txlock=immediate,
+// тож база заблокована для інших записів до кінця транзакції
+func sqliteBatchApply(ctx context.Context, tx *sql.Tx, userID int, op models.BatchOperation, result *BatchResult) error {
+	query := "SELECT id, amount, category, date, user_id, version FROM expenses WHERE id = ? AND user_id = ? AND deleted_at IS NULL"
+	var before models.Expense
+	err := tx.QueryRowContext(ctx, query, op.ID, userID).Scan(&before.ID, &before.Amount, &before.Category, &before.Date, &before.UserID, &before.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if before.Version != op.Version {
+		return ErrVersionConflict
+	}
+
+	after := before
+	after.Version++
+
+	switch op.Op {
+	case models.BatchUpdate:
+		after.Amount = op.Expense.Amount
+		after.Category = op.Expense.Category
+		after.Date = op.Expense.Date
+
+		query = "UPDATE expenses SET amount = ?, category = ?, date = ?, version = version + 1 WHERE id = ?"
+		_, err = tx.ExecContext(ctx, query, after.Amount, after.Category, after.Date.UTC(), after.ID)
+	case models.BatchDelete:
+		deletedAt := time.Now().UTC()
+		after.DeletedAt = &deletedAt
+
+		query = "UPDATE expenses SET deleted_at = ?, version = version + 1 WHERE id = ?"
+		_, err = tx.ExecContext(ctx, query, deletedAt, after.ID)
+	default:
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	*result = BatchResult{Before: &before, After: &after}
+	return nil
+}
+
+// sqliteInsertExpenses зберігає витрати одним багаторядковим INSERT та повертає їхні ID.
+// SQLite повертає rowid останнього рядка; в межах одного INSERT rowid ідуть послідовно.
+func sqliteInsertExpenses(ctx context.Context, q execer, expenses []models.Expense) ([]int, error) {
+	query := "INSERT INTO expenses (amount, category, date, user_id) VALUES " +
+		strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(expenses)), ", ")
+
+	args := make([]interface{}, 0, 4*len(expenses))
+	for _, expense := range expenses {
+		args = append(args, expense.Amount, expense.Category, expense.Date.UTC(), expense.UserID)
+	}
+
+	res, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	first := int(last) - len(expenses) + 1
+	ids := make([]int, len(expenses))
+	for i := range ids {
+		ids[i] = first + i
+	}
+
+	return ids, nil
+}
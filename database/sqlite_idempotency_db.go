@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "modernc.org/sqlite"
+)
+
+// --------------------------- Логіка роботи з ключами ідемпотентності (SQLite) ---------------------------
+type SQLiteIdempotencyDB struct {
+	DB *sql.DB
+}
+
+func (db *SQLiteIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	return reserveWithRetry(record, func() (models.IdempotencyRecord, bool, error) {
+		return db.reserve(ctx, record)
+	})
+}
+
+func (db *SQLiteIdempotencyDB) reserve(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	// Прострочений ключ можна використати повторно
+	query := "DELETE FROM idempotency_keys WHERE user_id = ? AND idem_key = ? AND expires_at <= ?"
+	_, err := db.DB.ExecContext(ctx, query, record.UserID, record.Key, record.CreatedAt.UTC())
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	query = `INSERT INTO idempotency_keys (user_id, idem_key, fingerprint, status_code, created_at, expires_at)
+		VALUES (?, ?, ?, 0, ?, ?) ON CONFLICT (user_id, idem_key) DO NOTHING`
+	res, err := db.DB.ExecContext(ctx, query, record.UserID, record.Key, record.Fingerprint, record.CreatedAt.UTC(), record.ExpiresAt.UTC())
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	if checkAffected(res) == nil {
+		return record, true, nil
+	}
+
+	// Ключ уже зайнятий - повертаємо збережений запис
+	existing, err := getIdempotencyKey(ctx, db.DB, record.UserID, record.Key)
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (db *SQLiteIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE idempotency_keys SET status_code = ?, response_headers = ?, response_body = ? WHERE user_id = ? AND idem_key = ?"
+	res, err := db.DB.ExecContext(ctx, query, record.StatusCode, string(headers), record.Body, record.UserID, record.Key)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (db *SQLiteIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE user_id = ? AND idem_key = ?"
+	_, err := db.DB.ExecContext(ctx, query, userID, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (db *SQLiteIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := "DELETE FROM idempotency_keys WHERE expires_at <= ?"
+	res, err := db.DB.ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
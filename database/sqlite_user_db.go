@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	_ "modernc.org/sqlite"
+)
+
+// --------------------------- Логіка роботи з даними для юзера (SQLite) ---------------------------
+type SQLiteUserDB struct {
+	DB *sql.DB
+}
+
+func (db *SQLiteUserDB) AddUser(ctx context.Context, user models.User) error {
+	_, err := db.DB.ExecContext(ctx, "INSERT INTO users(username, password) VALUES(?, ?)", user.Username, user.Password)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (db *SQLiteUserDB) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (models.User, error) {
+	var user models.User
+	err := db.DB.QueryRowContext(ctx, "SELECT id, username FROM users WHERE username = ? AND password = ?", username, password).Scan(&user.ID, &user.Username)
+	if err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+func (db *SQLiteUserDB) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	err := db.DB.QueryRowContext(ctx, "SELECT id, username FROM users WHERE username = ?", username).Scan(&user.ID, &user.Username)
+	if err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+func (db *SQLiteUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	var user models.User
+	err := db.DB.QueryRowContext(ctx, "SELECT id, username FROM users WHERE id = ?", userID).Scan(&user.ID, &user.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.User{}, ErrNotFound
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
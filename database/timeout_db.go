@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// WithTimeout повертає копію storage, у якій кожна операція сховищ обмежена тайм-аутом timeout,
+// а пакетні операції (ApplyBatch) - довшим batchTimeout (поверх дедлайну вхідного контексту).
+// Тайм-аут <= 0 - без обмеження. Операція, що не встигла, завершується з помилкою context.DeadlineExceeded.
+func (s *Storage) WithTimeout(timeout, batchTimeout time.Duration) *Storage {
+	if timeout <= 0 && batchTimeout <= 0 {
+		return s
+	}
+
+	limited := *s
+	limited.Expenses = &timeoutExpenseDB{next: s.Expenses, timeout: timeout, batchTimeout: batchTimeout}
+	limited.Users = &timeoutUserDB{next: s.Users, timeout: timeout}
+	limited.History = &timeoutExpenseHistoryDB{next: s.History, timeout: timeout}
+	limited.Idempotency = &timeoutIdempotencyDB{next: s.Idempotency, timeout: timeout}
+	return &limited
+}
+
+// limit обмежує ctx тайм-аутом timeout; timeout <= 0 - без обмеження
+func limit(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// --------------------------- Тайм-аути операцій з витратами ---------------------------
+type timeoutExpenseDB struct {
+	next         ExpenseDB
+	timeout      time.Duration
+	batchTimeout time.Duration
+}
+
+func (db *timeoutExpenseDB) GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetUserExpenses(ctx, userID)
+}
+
+func (db *timeoutExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetExpenseByID(ctx, userID, expenseID)
+}
+
+func (db *timeoutExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit Audit) (int, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.AddExpense(ctx, expense, audit)
+}
+
+func (db *timeoutExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit Audit) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.DeleteExpense(ctx, userID, expenseID, version, audit)
+}
+
+func (db *timeoutExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit Audit) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.UpdateUserExpenses(ctx, userID, expense, audit)
+}
+
+func (db *timeoutExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error) {
+	ctx, cancel := limit(ctx, db.batchTimeout)
+	defer cancel()
+	return db.next.ApplyBatch(ctx, userID, ops, atomic, audit)
+}
+
+func (db *timeoutExpenseDB) GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetUserTrash(ctx, userID)
+}
+
+func (db *timeoutExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.RestoreExpense(ctx, userID, expenseID, audit)
+}
+
+func (db *timeoutExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit Audit) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.PurgeExpense(ctx, userID, expenseID, audit)
+}
+
+func (db *timeoutExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.PurgeTrash(ctx, deletedBefore)
+}
+
+// --------------------------- Тайм-аути операцій з юзерами ---------------------------
+type timeoutUserDB struct {
+	next    UserDB
+	timeout time.Duration
+}
+
+func (db *timeoutUserDB) AddUser(ctx context.Context, user models.User) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.AddUser(ctx, user)
+}
+
+func (db *timeoutUserDB) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (models.User, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetUserByUsernameAndPassword(ctx, username, password)
+}
+
+func (db *timeoutUserDB) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetUserByUsername(ctx, username)
+}
+
+func (db *timeoutUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetUserByID(ctx, userID)
+}
+
+// --------------------------- Тайм-аути операцій з історією змін ---------------------------
+type timeoutExpenseHistoryDB struct {
+	next    ExpenseHistoryDB
+	timeout time.Duration
+}
+
+func (db *timeoutExpenseHistoryDB) GetExpenseHistory(ctx context.Context, userID, expenseID int) ([]models.ExpenseRevision, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetExpenseHistory(ctx, userID, expenseID)
+}
+
+func (db *timeoutExpenseHistoryDB) GetRevision(ctx context.Context, userID, expenseID, revisionID int) (models.ExpenseRevision, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.GetRevision(ctx, userID, expenseID, revisionID)
+}
+
+// --------------------------- Тайм-аути операцій з ключами ідемпотентності ---------------------------
+type timeoutIdempotencyDB struct {
+	next    IdempotencyDB
+	timeout time.Duration
+}
+
+func (db *timeoutIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.ReserveIdempotencyKey(ctx, record)
+}
+
+func (db *timeoutIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.CompleteIdempotencyKey(ctx, record)
+}
+
+func (db *timeoutIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.ReleaseIdempotencyKey(ctx, userID, key)
+}
+
+func (db *timeoutIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := limit(ctx, db.timeout)
+	defer cancel()
+	return db.next.PurgeExpiredIdempotencyKeys(ctx, now)
+}
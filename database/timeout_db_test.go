@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// blockingUserDB чекає на завершення контексту, як запит до СУБД, що не відповідає
+type blockingUserDB struct {
+	UserDB
+}
+
+func (db *blockingUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	<-ctx.Done()
+	return models.User{}, ctx.Err()
+}
+
+// blockingExpenseDB чекає на завершення контексту в пакетній операції
+type blockingExpenseDB struct {
+	ExpenseDB
+}
+
+func (db *blockingExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit Audit) ([]BatchResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestStorageWithTimeout(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.Users = &blockingUserDB{UserDB: storage.Users}
+
+	limited := storage.WithTimeout(20*time.Millisecond, 0)
+
+	start := time.Now()
+	_, err := limited.Users.GetUserByID(t.Context(), 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Отримано помилку %v, очікувалася %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Операцію перервано через %v, очікувалося близько 20ms", elapsed)
+	}
+
+	// Інші операції виконуються як звичайно
+	if err := limited.Users.AddUser(t.Context(), models.User{Username: "user", Password: "12345"}); err != nil {
+		t.Errorf("AddUser returned error: %v", err)
+	}
+
+	// Нульовий тайм-аут не обгортає сховища
+	if storage.WithTimeout(0, 0) != storage {
+		t.Error("WithTimeout(0, 0) має повертати те саме сховище")
+	}
+}
+
+func TestStorageWithTimeout_Batch(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.Expenses = &blockingExpenseDB{ExpenseDB: storage.Expenses}
+
+	limited := storage.WithTimeout(20*time.Millisecond, 200*time.Millisecond)
+
+	// Пакетна операція обмежена власним, довшим тайм-аутом
+	start := time.Now()
+	_, err := limited.Expenses.ApplyBatch(t.Context(), 1, nil, true, Audit{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Отримано помилку %v, очікувалася %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Пакет перервано через %v, очікувалося близько 200ms", elapsed)
+	}
+}
@@ -8,18 +8,29 @@ function getToken() {
   return localStorage.getItem("token");
 }
 
-function deleteExpense(expenseID) {
+function deleteExpense(expenseID, version) {
+  if (!confirm("Move this expense to the trash?")) {
+    return;
+  }
+
   const options = {
     method: "DELETE",
     headers: {
       Authorization: getToken(),
+      "If-Match": `"${version}"`,
     },
   };
-  fetch("/expenses/" + expenseID, options)
+  fetch("/api/v1/expenses/" + expenseID, options)
     .then((response) => {
       if (response.ok) {
-        alert("Expense deleted successfully");
         fetchExpenses(); // Refresh the expenses table
+        fetchTrash();
+        if (confirm("Expense moved to the trash. Undo?")) {
+          restoreExpense(expenseID);
+        }
+      } else if (response.status === 412) {
+        alert("The expense was changed elsewhere. The list has been refreshed.");
+        fetchExpenses();
       } else {
         alert("Failed to delete expense");
       }
@@ -29,9 +40,105 @@ function deleteExpense(expenseID) {
     });
 }
 
+function restoreExpense(expenseID) {
+  const options = {
+    method: "POST",
+    headers: {
+      Authorization: getToken(),
+    },
+  };
+  fetch("/api/v1/expenses/trash/" + expenseID + "/restore", options)
+    .then((response) => {
+      if (response.ok) {
+        fetchExpenses();
+        fetchTrash();
+      } else {
+        alert("Failed to restore expense");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+    });
+}
+
+function purgeExpense(expenseID) {
+  if (!confirm("Delete this expense permanently? This cannot be undone.")) {
+    return;
+  }
+
+  const options = {
+    method: "DELETE",
+    headers: {
+      Authorization: getToken(),
+    },
+  };
+  fetch("/api/v1/expenses/trash/" + expenseID, options)
+    .then((response) => {
+      if (response.ok) {
+        fetchTrash();
+      } else {
+        alert("Failed to delete expense permanently");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+    });
+}
+
+// Fetch trashed expenses and display them in the trash table
+function fetchTrash() {
+  const options = {
+    headers: {
+      Authorization: getToken(),
+    },
+  };
+
+  fetch("/api/v1/expenses/trash", options)
+    .then((response) => response.json())
+    .then((expenses) => {
+      const trashList = document.getElementById("trash-list");
+      trashList.innerHTML = "";
+
+      (expenses || []).forEach((expense) => {
+        const row = document.createElement("tr");
+        const categoryCell = document.createElement("td");
+        const amountCell = document.createElement("td");
+        const deletedCell = document.createElement("td");
+        const actionCell = document.createElement("td");
+        const restoreButton = document.createElement("button");
+        const purgeButton = document.createElement("button");
+
+        categoryCell.innerText = expense.category;
+        amountCell.innerText = expense.amount;
+        deletedCell.innerText = new Date(expense.deleted_at).toLocaleString();
+        restoreButton.innerText = "Restore";
+        purgeButton.innerText = "Delete forever";
+
+        restoreButton.addEventListener("click", function () {
+          restoreExpense(expense.id);
+        });
+
+        purgeButton.addEventListener("click", function () {
+          purgeExpense(expense.id);
+        });
+
+        actionCell.appendChild(restoreButton);
+        actionCell.appendChild(purgeButton);
+        row.appendChild(categoryCell);
+        row.appendChild(amountCell);
+        row.appendChild(deletedCell);
+        row.appendChild(actionCell);
+        trashList.appendChild(row);
+      });
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+    });
+}
+
 // Fetch expenses data and display them in the table
 function fetchExpenses(sortBy) {
-  let url = "/expenses";
+  let url = "/api/v1/expenses";
   if (sortBy) {
     url += `?sort=${sortBy}`;
   }
@@ -64,7 +171,7 @@ function fetchExpenses(sortBy) {
         updateButton.innerText = "Update";
       
         deleteButton.addEventListener("click", function () {
-          deleteExpense(expense.id);
+          deleteExpense(expense.id, expense.version);
         });
       
         updateButton.addEventListener("click", function () {
@@ -89,8 +196,13 @@ function fetchExpenses(sortBy) {
     });
 }
 
+// Idempotency key of the expense being added; reused when the same expense is resubmitted
+// (double click, retry after a network error) so it is created only once
+let addExpenseKey = null;
+let addExpenseBody = null;
+
 document
-  .querySelector('form[action="/expenses"]')
+  .querySelector('form[action="/api/v1/expenses"]')
   .addEventListener("submit", function (e) {
     e.preventDefault();
     const form = e.target;
@@ -99,18 +211,25 @@ document
       category: formData.get("category"),
       amount: parseInt(formData.get("amount")),
     };
+    const body = JSON.stringify(data);
+    if (body !== addExpenseBody) {
+      addExpenseKey = crypto.randomUUID();
+      addExpenseBody = body;
+    }
     const options = {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
         Authorization: getToken(),
+        "Idempotency-Key": addExpenseKey,
       },
-      body: JSON.stringify(data),
+      body: body,
     };
 
     fetch(form.action, options)
       .then((response) => {
         if (response.ok) {
+          addExpenseBody = null;
           alert("Expenses add successful");
         } else {
           alert("Expenses add failure");
@@ -127,6 +246,11 @@ document.getElementById("get-expenses").addEventListener("click", function () {
   fetchExpenses(sortBy);
 });
 
+// Trash Button Event Listener
+document.getElementById("get-trash").addEventListener("click", function () {
+  fetchTrash();
+});
+
 function openUpdateExpensePage(expenseID) {
     window.location.href = "expensesupdate.html?expenseID=" + expenseID;
   }
@@ -1,45 +1,92 @@
 const urlParams = new URLSearchParams(window.location.search);
 const expenseID = urlParams.get("expenseID");
 
-fetch("/expenses/" + expenseID)
-  .then((response) => response.json())
-  .then((expense) => {
-    const categoryInput = document.getElementById("update-category");
-    const amountInput = document.getElementById("update-amount");
-    const dateInput = document.getElementById("update-date");
-
-    categoryInput.value = expense.category;
-    amountInput.value = expense.amount;
-    dateInput.value = expense.date; 
-  })
-  .catch((error) => {
-    console.error("Error:", error);
-  });
+// ETag of the loaded version; sent back as If-Match so concurrent edits are detected
+let expenseETag = null;
+
+// Values as loaded from the server; only fields that differ are sent in the PATCH
+let original = {};
+
+function loadExpense() {
+  fetch("/api/v1/expenses/" + expenseID, { headers: { Authorization: getToken() } })
+    .then((response) => {
+      if (response.status === 404) {
+        alert("Expense not found");
+        window.location.href = "expenses.html";
+        return null;
+      }
+      expenseETag = response.headers.get("ETag");
+      return response.json();
+    })
+    .then((expense) => {
+      if (!expense) {
+        return;
+      }
+
+      const categoryInput = document.getElementById("update-category");
+      const amountInput = document.getElementById("update-amount");
+      const dateInput = document.getElementById("update-date");
+
+      original = {
+        category: expense.category,
+        amount: expense.amount,
+        rawdate: expense.date.slice(0, 10),
+      };
+
+      categoryInput.value = original.category;
+      amountInput.value = original.amount;
+      dateInput.value = original.rawdate;
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+    });
+}
+
+loadExpense();
 
 document.getElementById("update-expense-form").addEventListener("submit", function (e) {
   e.preventDefault();
   const form = e.target;
   const formData = new FormData(form);
-  const data = {
-    id: parseInt(expenseID),
-    rawdate: formData.get("rawdate"), 
+  const current = {
+    rawdate: formData.get("rawdate"),
     category: formData.get("category"),
     amount: parseInt(formData.get("amount")),
   };
+
+  // JSON Merge Patch: only the changed fields
+  const patch = {};
+  Object.keys(current).forEach((field) => {
+    if (current[field] !== original[field]) {
+      patch[field] = current[field];
+    }
+  });
+
+  if (Object.keys(patch).length === 0) {
+    window.location.href = "expenses.html";
+    return;
+  }
+
   const options = {
-    method: "PUT",
+    method: "PATCH",
     headers: {
-      "Content-Type": "application/json",
+      "Content-Type": "application/merge-patch+json",
       Authorization: getToken(),
+      "If-Match": expenseETag,
     },
-    body: JSON.stringify(data),
+    body: JSON.stringify(patch),
   };
 
-  fetch("/expenses/" + expenseID, options)
+  fetch("/api/v1/expenses/" + expenseID, options)
     .then((response) => {
       if (response.ok) {
         alert("Expense updated successfully");
         window.location.href = "expenses.html";
+      } else if (response.status === 412) {
+        alert("The expense was changed in another tab. The latest version has been loaded.");
+        loadExpense();
+      } else if (response.status === 404) {
+        alert("Expense not found");
       } else {
         alert("Failed to update expense");
       }
@@ -49,6 +96,85 @@ document.getElementById("update-expense-form").addEventListener("submit", functi
     });
 });
 
+// Fetch the change history of the expense and display it in the history table
+function fetchHistory() {
+  const options = {
+    headers: {
+      Authorization: getToken(),
+    },
+  };
+
+  fetch("/api/v1/expenses/" + expenseID + "/history", options)
+    .then((response) => response.json())
+    .then((revisions) => {
+      const historyList = document.getElementById("history-list");
+      historyList.innerHTML = "";
+
+      revisions.forEach((revision) => {
+        const state = revision.after || revision.before || {};
+        const row = document.createElement("tr");
+        const whenCell = document.createElement("td");
+        const actionCell = document.createElement("td");
+        const categoryCell = document.createElement("td");
+        const amountCell = document.createElement("td");
+        const revertCell = document.createElement("td");
+
+        whenCell.innerText = new Date(revision.created_at).toLocaleString();
+        actionCell.innerText = revision.action;
+        categoryCell.innerText = state.category;
+        amountCell.innerText = state.amount;
+
+        if (revision.after && !revision.after.deleted_at) {
+          const revertButton = document.createElement("button");
+          revertButton.innerText = "Revert to this";
+          revertButton.addEventListener("click", function () {
+            revertExpense(revision.id);
+          });
+          revertCell.appendChild(revertButton);
+        }
+
+        row.appendChild(whenCell);
+        row.appendChild(actionCell);
+        row.appendChild(categoryCell);
+        row.appendChild(amountCell);
+        row.appendChild(revertCell);
+        historyList.appendChild(row);
+      });
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+    });
+}
+
+function revertExpense(revisionID) {
+  if (!confirm("Revert the expense to this version?")) {
+    return;
+  }
+
+  const options = {
+    method: "POST",
+    headers: {
+      Authorization: getToken(),
+    },
+  };
+
+  fetch("/api/v1/expenses/" + expenseID + "/history/" + revisionID + "/revert", options)
+    .then((response) => {
+      if (response.ok) {
+        alert("Expense reverted successfully");
+        loadExpense();
+        fetchHistory();
+      } else {
+        alert("Failed to revert expense");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+    });
+}
+
+fetchHistory();
+
 // Function to retrieve token from local storage
 function getToken() {
   return localStorage.getItem("token");
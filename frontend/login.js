@@ -10,7 +10,7 @@ function getToken() {
 
 // JSON for log form
 document
-  .querySelector('form[action="/login"]')
+  .querySelector('form[action="/api/v1/login"]')
   .addEventListener("submit", function (e) {
     e.preventDefault();
     const form = e.target;
@@ -1,6 +1,6 @@
 // Set content type header to JSON for registration form
 document
-  .querySelector('form[action="/register"]')
+  .querySelector('form[action="/api/v1/register"]')
   .addEventListener("submit", function (e) {
     e.preventDefault();
     const form = e.target;
@@ -20,7 +20,13 @@ document
           alert("Registration successful");
           window.location.href = "login.html"; // Перехід на login.html
         } else {
-          alert("Registration failed");
+          // problem+json: list the invalid fields if there are any
+          return response.json().then((problem) => {
+            const details = (problem.errors || [])
+              .map((e) => e.field + " " + e.message)
+              .join("\n");
+            alert("Registration failed: " + (details || problem.detail));
+          });
         }
       })
       .catch((error) => {
@@ -2,101 +2,24 @@
 package handlers
 
 import (
-	"database/sql"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/database/dbtest"
 	"github.com/ChomuCake/uni-golang-labs/models"
 	"github.com/ChomuCake/uni-golang-labs/util"
 )
 
-const testDBName = "benchmark_test_db"
-
-func InitTestDB() (*sql.DB, error) {
-	// Формування рядка підключення до тестової бази даних
-	dsn := "root:12345@tcp(localhost:3306)/" + testDBName + "?parseTime=true"
-
-	// Встановлення з'єднання з тестовою базою даних
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to test database: %v", err)
-	}
-
-	// Очищення тестової бази даних перед початком тестів
-	if err := СlearTestDB(db); err != nil {
-		return nil, fmt.Errorf("failed to clear test database: %v", err)
-	}
-
-	return db, nil
-}
-
-func СlearTestDB(db *sql.DB) error {
-	// Видалення і створення бази даних
-	_, err := db.Exec("DROP DATABASE IF EXISTS " + testDBName)
-	if err != nil {
-		return fmt.Errorf("failed to drop test database: %v", err)
-	}
-
-	_, err = db.Exec("CREATE DATABASE " + testDBName)
-	if err != nil {
-		return fmt.Errorf("failed to create test database: %v", err)
-	}
-
-	_, err = db.Exec("USE " + testDBName)
-	if err != nil {
-		return fmt.Errorf("failed to switch to test database: %v", err)
-	}
-
-	// Створення таблиці `users`
-	_, err = db.Exec(`
-		CREATE TABLE users (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			username VARCHAR(255) NOT NULL,
-			password VARCHAR(255) NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create users table: %v", err)
-	}
-
-	// Створення таблиці `expenses`
-	_, err = db.Exec(`
-		CREATE TABLE expenses (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			date DATE NOT NULL,
-			category VARCHAR(255) NOT NULL,
-			amount INT NOT NULL,
-			user_id INT NOT NULL,
-			FOREIGN KEY (user_id) REFERENCES users(id)
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create expenses table: %v", err)
-	}
-
-	return nil
-}
-
 func BenchmarkGetUserExpenses(b *testing.B) {
-	testDB, err := InitTestDB()
-	if err != nil {
-		b.Fatalf("Failed to initialize test database: %v", err)
-	}
-	defer testDB.Close()
+	ctx := b.Context()
 
-	expenseDB := &database.MySQLExpenseDB{
-		DB: testDB,
-	}
-
-	userDB := &database.MySQLUserDB{
-		DB: testDB,
-	}
+	storage := dbtest.NewStorage(b)
+	expenseDB, userDB := storage.Expenses, storage.Users
 
-	jwtToken := &util.JWTTokenManager{}
+	jwtToken := &util.JWTTokenManager{Secret: []byte("benchmark-secret-0123456789abcdef")}
 
 	benchmarkUser := models.User{
 		ID:       1,
@@ -111,12 +34,12 @@ func BenchmarkGetUserExpenses(b *testing.B) {
 		UserID:   1,
 	}
 
-	err = userDB.AddUser(benchmarkUser)
+	err := userDB.AddUser(ctx, benchmarkUser)
 	if err != nil {
 		b.Errorf("failed to add user with error: %v", err)
 	}
 
-	err = expenseDB.AddExpense(benmarkExpense)
+	_, err = expenseDB.AddExpense(ctx, benmarkExpense, database.Audit{ActorID: benmarkExpense.UserID})
 	if err != nil {
 		b.Errorf("failed to add expense with error: %v", err)
 	}
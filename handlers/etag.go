@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// versionETag формує ETag окремої витрати з її версії
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagMatches перевіряє, чи містить заголовок If-Match/If-None-Match вказаний ETag (або "*").
+// За слабкого порівняння (If-None-Match) W/"..." збігається з "...", за сильного (If-Match)
+// слабкий ETag не збігається ні з чим (RFC 9110, розділ 8.8.3.2).
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch перевіряє передумову If-Match для зміни витрати з поточною версією version.
+// Повертає nil, якщо зміну дозволено, або помилку для відповіді (428 чи 412).
+func checkIfMatch(r *http.Request, version int) *Problem {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return NewProblem(http.StatusPreconditionRequired, "send the expense ETag in the If-Match header")
+	}
+
+	if !etagMatches(ifMatch, versionETag(version), false) {
+		return versionConflictProblem()
+	}
+
+	return nil
+}
+
+// writeJSONWithETag кодує v у JSON та встановлює ETag відповіді.
+// Якщо клієнт уже має актуальну копію (If-None-Match), повертається 304 без тіла.
+// Якщо etag порожній, він обчислюється з вмісту відповіді.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v interface{}, etag string) {
+	var body bytes.Buffer
+	err := json.NewEncoder(&body).Encode(v)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	if etag == "" {
+		sum := sha256.Sum256(body.Bytes())
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+
+	// Відповідь залежить від користувача, тому кешується лише клієнтом і завжди перевіряється
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Vary", "Authorization")
+
+	isRead := r.Method == http.MethodGet || r.Method == http.MethodHead
+	if ifNoneMatch := r.Header.Get("If-None-Match"); isRead && ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
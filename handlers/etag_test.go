@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// racingExpenseDB імітує паралельний запит, який змінює витрату одразу після того,
+// як обробник прочитав її поточну версію
+type racingExpenseDB struct {
+	database.ExpenseDB
+}
+
+func (db *racingExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	expense, err := db.ExpenseDB.GetExpenseByID(ctx, userID, expenseID)
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	concurrent := expense
+	concurrent.Amount++
+	err = db.ExpenseDB.UpdateUserExpenses(ctx, userID, concurrent, database.Audit{ActorID: userID, RequestID: "concurrent"})
+	if err != nil {
+		return models.Expense{}, err
+	}
+
+	return expense, nil
+}
+
+// ---------------- ETAG TESTS --------------------
+func TestExpensesHandler_GetExpense_ReturnsETag(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	if etag := rr.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("Отримано некоректний ETag: отримано %v, очікувалося %v", etag, `"1"`)
+	}
+}
+
+func TestExpensesHandler_GetExpense_NotModified(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-None-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotModified {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotModified)
+	}
+
+	if rr.Body.Len() != 0 {
+		t.Errorf("Відповідь 304 містить тіло: %q", rr.Body.String())
+	}
+}
+
+func TestExpensesHandler_GetExpense_NotModifiedWeakETag(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-None-Match", `W/"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert: If-None-Match використовує слабке порівняння
+	if status := rr.Code; status != http.StatusNotModified {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotModified)
+	}
+}
+
+func TestExpensesHandler_GetExpense_NotFound(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/99", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_GetExpenses_NotModified(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+
+	first, err := http.NewRequest("GET", "/expenses?sort=all", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Header.Set("Token", "Correct")
+
+	rr := httptest.NewRecorder()
+	handler.Handle(rr, first)
+
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Відповідь зі списком витрат не містить ETag")
+	}
+
+	req, err := http.NewRequest("GET", "/expenses?sort=all", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-None-Match", etag)
+
+	rr = httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotModified {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotModified)
+	}
+}
+
+func TestExpensesHandler_GetExpenses_ChangedCollection(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses?sort=all", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-None-Match", `"stale"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+}
+
+func TestExpensesHandler_PutExpense_PreconditionRequired(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusPreconditionRequired {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionRequired)
+	}
+}
+
+func TestExpensesHandler_PutExpense_StaleETag(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"0"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusPreconditionFailed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionFailed)
+	}
+}
+
+func TestExpensesHandler_PutExpense_ConcurrentUpdate(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &racingExpenseDB{ExpenseDB: handler.ExpenseDB}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusPreconditionFailed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionFailed)
+	}
+}
+
+func TestExpensesHandler_PutExpense_ReturnsNewETag(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	if etag := rr.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("Отримано некоректний ETag: отримано %v, очікувалося %v", etag, `"2"`)
+	}
+}
+
+func TestExpensesHandler_PutExpense_WeakETag(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `W/"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert: If-Match використовує сильне порівняння, тож слабкий ETag не збігається
+	if status := rr.Code; status != http.StatusPreconditionFailed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionFailed)
+	}
+}
+
+func TestExpensesHandler_DeleteExpense_PreconditionRequired(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusPreconditionRequired {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionRequired)
+	}
+}
+
+func TestExpensesHandler_DeleteExpense_StaleETag(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"5"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusPreconditionFailed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionFailed)
+	}
+}
+
+// -------------- END ETAG TESTS --------------
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// maxBatchOperations обмежує кількість операцій в одному пакетному запиті
+const maxBatchOperations = 1000
+
+// batch обробляє POST /expenses/batch - створення, зміну та видалення витрат одним запитом.
+// Усі операції та їхні ревізії історії записуються в одній транзакції: у режимі "atomic" помилка будь-якої операції
+// скасовує весь пакет (422), у режимі "partial" кожна операція має власний результат (207 при помилках).
+func (h *ExpenseHandler) batch(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var batch models.BatchRequest
+	if problem := decodeJSON(w, r, &batch, maxBatchBodyBytes, "request body must be a JSON batch object"); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+
+	if batch.Mode == "" {
+		batch.Mode = models.BatchModeAtomic
+	}
+	if batch.Mode != models.BatchModeAtomic && batch.Mode != models.BatchModePartial {
+		writeProblem(w, r, ValidationProblem("invalid batch", FieldError{Field: "mode", Message: "must be atomic or partial"}))
+		return
+	}
+
+	if len(batch.Operations) == 0 {
+		writeProblem(w, r, ValidationProblem("invalid batch", FieldError{Field: "operations", Message: "must not be empty"}))
+		return
+	}
+	if len(batch.Operations) > maxBatchOperations {
+		writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("a batch may contain at most %d operations", maxBatchOperations))
+		return
+	}
+
+	err := prepareBatchOperations(batch.Operations)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	atomic := batch.Mode == models.BatchModeAtomic
+	results, err := h.ExpenseDB.ApplyBatch(r.Context(), existingUser.ID, batch.Operations, atomic, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	response := models.BatchResponse{
+		Mode:    batch.Mode,
+		Results: make([]models.BatchItemResult, len(results)),
+	}
+	failed := false
+
+	for i, result := range results {
+		item := models.BatchItemResult{
+			Index: i,
+			Op:    batch.Operations[i].Op,
+		}
+
+		if result.Err != nil {
+			failed = true
+			item.Status = batchErrorStatus(result.Err)
+			item.Error = http.StatusText(item.Status)
+		} else {
+			response.Committed = true
+			item.Status = http.StatusOK
+			if item.Op == models.BatchCreate {
+				item.Status = http.StatusCreated
+			}
+			item.Expense = result.After
+		}
+
+		response.Results[i] = item
+	}
+
+	status := http.StatusOK
+	if failed && atomic {
+		status = http.StatusUnprocessableEntity
+	} else if failed {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// prepareBatchOperations перевіряє операції пакета та розбирає дати.
+// Для create дата необов'язкова (за замовчуванням - поточний час), для update - обов'язкова.
+// Помилки всіх операцій повертаються разом у ValidationProblem з полями виду operations[i].field.
+func prepareBatchOperations(ops []models.BatchOperation) error {
+	var fieldErrs []FieldError
+	invalid := func(i int, field, message string) {
+		fieldErrs = append(fieldErrs, FieldError{Field: fmt.Sprintf("operations[%d].%s", i, field), Message: message})
+	}
+
+	for i := range ops {
+		op := &ops[i]
+
+		if op.Op == models.BatchCreate || op.Op == models.BatchUpdate {
+			errs := validation.Struct(op.Expense)
+			fieldErrs = append(fieldErrs, validation.Prefix(fmt.Sprintf("operations[%d].expense", i), errs)...)
+		}
+
+		// Формат дати перевірено правилом date, тож помилку розбору можна не обробляти
+		parsedDate, dateErr := time.Parse(validation.DateLayout, op.Expense.RawDate)
+
+		switch op.Op {
+		case models.BatchCreate:
+			op.Expense.Date = time.Now()
+			if dateErr == nil {
+				op.Expense.Date = parsedDate
+			}
+		case models.BatchUpdate:
+			if op.ID <= 0 {
+				invalid(i, "id", "is required for update")
+			}
+			if op.Version <= 0 {
+				invalid(i, "version", "is required for update")
+			}
+			if op.Expense.RawDate == "" {
+				invalid(i, "expense.rawdate", "is required for update")
+			}
+			op.Expense.Date = parsedDate
+		case models.BatchDelete:
+			if op.ID <= 0 {
+				invalid(i, "id", "is required for delete")
+			}
+			if op.Version <= 0 {
+				invalid(i, "version", "is required for delete")
+			}
+		default:
+			invalid(i, "op", "must be one of create, update, delete")
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return ValidationProblem("invalid batch operations", fieldErrs...)
+	}
+	return nil
+}
+
+// batchErrorStatus перетворює помилку операції пакета на статус-код, який повернув би окремий запит
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, db.ErrBatchAborted):
+		return http.StatusFailedDependency
+	default:
+		if status, ok := contextErrorStatus(err); ok {
+			return status
+		}
+		return http.StatusInternalServerError
+	}
+}
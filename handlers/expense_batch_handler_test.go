@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// ---------------- BATCH TESTS --------------------
+func TestExpensesHandler_Batch(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses/batch", nil, `{"operations": [
+		{"op": "create", "expense": {"category": "food", "amount": 10, "rawdate": "2023-05-27"}},
+		{"op": "update", "id": 1, "version": 1, "expense": {"category": "rent", "amount": 20, "rawdate": "2023-05-28"}},
+		{"op": "delete", "id": 2, "version": 1}
+	]}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	var response models.BatchResponse
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedStatuses := []int{http.StatusCreated, http.StatusOK, http.StatusOK}
+	if len(response.Results) != len(expectedStatuses) || !response.Committed || response.Mode != models.BatchModeAtomic {
+		t.Fatalf("Отримано некоректну відповідь: %+v", response)
+	}
+	for i, result := range response.Results {
+		if result.Status != expectedStatuses[i] || result.Index != i {
+			t.Errorf("Отримано некоректний результат операції %d: %+v", i, result)
+		}
+	}
+
+	// Кожна операція записала свою ревізію
+	createdID := response.Results[0].Expense.ID
+	for expenseID, action := range map[int]string{createdID: models.ActionCreate, 1: models.ActionUpdate, 2: models.ActionDelete} {
+		history := expenseHistory(t, handler, expenseID)
+		if len(history) == 0 || history[len(history)-1].Action != action {
+			t.Errorf("Не записано ревізію %q витрати %d: %+v", action, expenseID, history)
+		}
+	}
+}
+
+func TestExpensesHandler_Batch_AtomicFailure(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses/batch", nil, `{"mode": "atomic", "operations": [
+		{"op": "create", "expense": {"category": "food", "amount": 10}},
+		{"op": "delete", "id": 99, "version": 1}
+	]}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnprocessableEntity)
+	}
+
+	var response models.BatchResponse
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Committed || response.Results[0].Status != http.StatusFailedDependency ||
+		response.Results[1].Status != http.StatusNotFound {
+		t.Errorf("Отримано некоректну відповідь: %+v", response)
+	}
+
+	if expenses := userExpenses(t, handler); len(expenses) != 4 {
+		t.Errorf("Скасований пакет змінив витрати: %+v", expenses)
+	}
+}
+
+func TestExpensesHandler_Batch_PartialFailure(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses/batch", nil, `{"mode": "partial", "operations": [
+		{"op": "create", "expense": {"category": "food", "amount": 10}},
+		{"op": "delete", "id": 99, "version": 1}
+	]}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusMultiStatus {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusMultiStatus)
+	}
+
+	var response models.BatchResponse
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !response.Committed || response.Results[0].Status != http.StatusCreated ||
+		response.Results[1].Status != http.StatusNotFound {
+		t.Errorf("Отримано некоректну відповідь: %+v", response)
+	}
+}
+
+func TestExpensesHandler_Batch_InvalidRequests(t *testing.T) {
+	tooMany := `{"operations": [` +
+		strings.TrimSuffix(strings.Repeat(`{"op": "create", "expense": {"category": "food", "amount": 1}},`, maxBatchOperations+1), ",") + `]}`
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", `{"operations": "invalid"}`, http.StatusBadRequest},
+		{"unknown mode", `{"mode": "sometimes", "operations": [{"op": "create"}]}`, http.StatusBadRequest},
+		{"empty batch", `{"operations": []}`, http.StatusBadRequest},
+		{"unknown operation", `{"operations": [{"op": "upsert"}]}`, http.StatusBadRequest},
+		{"update without version", `{"operations": [{"op": "update", "id": 1, "expense": {"rawdate": "2023-05-27"}}]}`, http.StatusBadRequest},
+		{"update without date", `{"operations": [{"op": "update", "id": 1, "version": 1}]}`, http.StatusBadRequest},
+		{"delete without id", `{"operations": [{"op": "delete", "version": 1}]}`, http.StatusBadRequest},
+		{"too many operations", tooMany, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := newRequest(t, "POST", "/expenses/batch", nil, tt.body)
+
+			handler := SetUpHandlerDep(t)
+
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.Handle(rr, req)
+
+			// Assert
+			if status := rr.Code; status != tt.status {
+				t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+					status, tt.status)
+			}
+		})
+	}
+}
+
+func TestExpensesHandler_Batch_ServerError(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses/batch", nil, `{"operations": [{"op": "create", "expense": {"category": "food", "amount": 1}}]}`)
+
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusInternalServerError)
+	}
+}
+
+func TestExpensesHandler_Batch_IncorrectTokenInRequest(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses/batch", nil, `{"operations": [{"op": "create", "expense": {"category": "food", "amount": 1}}]}`)
+	req.Header.Set("Token", "Incorrect")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnauthorized)
+	}
+}
+
+func TestExpensesHandler_Batch_NotAllowed(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("NOTALLOWED", "/expenses/batch", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusMethodNotAllowed)
+	}
+	if allow := rr.Header().Get("Allow"); !strings.Contains(allow, http.MethodPost) {
+		t.Errorf("Заголовок Allow не містить POST: %q", allow)
+	}
+}
+
+// -------------- END BATCH TESTS --------------
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// maxPageLimit - найбільша кількість витрат на сторінці (параметр limit)
+const maxPageLimit = 1000
+
+// totalCountHeader - заголовок із кількістю витрат, що відповідають фільтру, без урахування limit та offset
+const totalCountHeader = "X-Total-Count"
+
+// expenseFilter - параметри запиту GET /expenses, що звужують перелік витрат.
+// Нульове значення не змінює перелік.
+type expenseFilter struct {
+	category string // Категорія без урахування регістру
+	from, to string // Межі дат включно, YYYY-MM-DD
+	limit    int    // Розмір сторінки; 0 - без обмеження
+	offset   int
+}
+
+// parseExpenseFilter розбирає параметри category, from, to, limit та offset
+func parseExpenseFilter(query url.Values) (expenseFilter, *Problem) {
+	filter := expenseFilter{
+		category: strings.TrimSpace(query.Get("category")),
+		from:     query.Get("from"),
+		to:       query.Get("to"),
+	}
+
+	var fieldErrs []FieldError
+	for _, date := range []struct{ name, value string }{{"from", filter.from}, {"to", filter.to}} {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.Parse(validation.DateLayout, date.value); err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: date.name, Message: "must be a date in YYYY-MM-DD format"})
+		}
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		if filter.limit, err = strconv.Atoi(value); err != nil || filter.limit < 1 || filter.limit > maxPageLimit {
+			fieldErrs = append(fieldErrs, FieldError{Field: "limit", Message: "must be a number from 1 to " + strconv.Itoa(maxPageLimit)})
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if filter.offset, err = strconv.Atoi(value); err != nil || filter.offset < 0 {
+			fieldErrs = append(fieldErrs, FieldError{Field: "offset", Message: "must be a non-negative number"})
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return expenseFilter{}, ValidationProblem("invalid query parameters", fieldErrs...)
+	}
+	return filter, nil
+}
+
+// apply повертає сторінку відфільтрованих витрат та загальну кількість витрат, що відповідають фільтру
+func (f expenseFilter) apply(expenses []models.Expense) ([]models.Expense, int) {
+	matched := expenses[:0:0]
+	for _, expense := range expenses {
+		date := expense.Date.Format(validation.DateLayout)
+		if f.category != "" && !strings.EqualFold(expense.Category, f.category) ||
+			f.from != "" && date < f.from ||
+			f.to != "" && date > f.to {
+			continue
+		}
+		matched = append(matched, expense)
+	}
+
+	total := len(matched)
+	matched = matched[min(f.offset, total):]
+	if f.limit > 0 && len(matched) > f.limit {
+		matched = matched[:f.limit]
+	}
+	return matched, total
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+func TestExpensesHandler_GetExpenses_Filter(t *testing.T) {
+	SetTimeNow()
+	yesterday := fixedTime.AddDate(0, 0, -1).Format(validation.DateLayout)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedIDs   []int
+		expectedTotal string
+	}{
+		{"page", "?sort=all&limit=2&offset=1", []int{3, 1}, "4"},
+		{"offset past the end", "?offset=10", []int{}, "4"},
+		{"date range", "?sort=all&from=" + yesterday + "&to=" + yesterday, []int{3}, "1"},
+		{"category", "?category=TEST&limit=1", []int{4}, "4"},
+		{"unknown category", "?category=rent", []int{}, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req, err := http.NewRequest("GET", "/expenses"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Token", "Correct")
+
+			handler := SetUpHandlerDep(t)
+
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.Handle(rr, req)
+
+			// Assert
+			if status := rr.Code; status != http.StatusOK {
+				t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+					status, http.StatusOK)
+			}
+
+			var expenses []models.Expense
+			if err := json.Unmarshal(rr.Body.Bytes(), &expenses); err != nil {
+				t.Fatal(err)
+			}
+			ids := []int{}
+			for _, expense := range expenses {
+				ids = append(ids, expense.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("Отримано витрати %v, очікувалося %v", ids, tt.expectedIDs)
+			}
+
+			if total := rr.Header().Get(totalCountHeader); total != tt.expectedTotal {
+				t.Errorf("Отримано %s %q, очікувалося %q", totalCountHeader, total, tt.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestExpensesHandler_GetExpenses_InvalidFilter(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses?limit=0&offset=-1&from=27.05.2023", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+
+	expected := []FieldError{
+		{Field: "from", Message: "must be a date in YYYY-MM-DD format"},
+		{Field: "limit", Message: "must be a number from 1 to 1000"},
+		{Field: "offset", Message: "must be a non-negative number"},
+	}
+	if problem := decodeProblem(t, rr); !reflect.DeepEqual(problem.Errors, expected) {
+		t.Errorf("Отримано помилки полів %v, очікувалося %v", problem.Errors, expected)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	db "github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/middleware"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// listHistory обробляє GET /expenses/{id}/history - перелік ревізій витрати у хронологічному порядку
+func (h *ExpenseHandler) listHistory(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	history, err := h.HistoryDB.GetExpenseHistory(r.Context(), existingUser.ID, expenseID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	// Немає жодної ревізії - витрата не існувала або належить іншому користувачу
+	if len(history) == 0 {
+		writeError(w, r, http.StatusNotFound, "expense not found")
+		return
+	}
+
+	writeJSONWithETag(w, r, history, "")
+}
+
+// revertExpense обробляє POST /expenses/{id}/history/{revision}/revert
+func (h *ExpenseHandler) revertExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+	revisionID, ok := pathInt(w, r, "revision")
+	if !ok {
+		return
+	}
+
+	h.revert(w, r, existingUser, expenseID, revisionID)
+}
+
+// revert повертає витрату до стану, зафіксованого після ревізії revisionID.
+// Саме повернення також записується в історію як окрема ревізія.
+func (h *ExpenseHandler) revert(w http.ResponseWriter, r *http.Request, user models.User, expenseID, revisionID int) {
+	revision, err := h.HistoryDB.GetRevision(r.Context(), user.ID, expenseID, revisionID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	var target models.Expense
+	if len(revision.After) == 0 || string(revision.After) == "null" {
+		writeError(w, r, http.StatusConflict, "the expense did not exist after this revision") // Після цієї ревізії витрати не існувало
+		return
+	}
+	err = json.Unmarshal(revision.After, &target)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	if target.DeletedAt != nil {
+		writeError(w, r, http.StatusConflict, "the expense was in the trash after this revision; restore it first") // Після цієї ревізії витрата була в кошику
+		return
+	}
+
+	// Витрата з кошика спочатку має бути відновлена
+	previousExpense, err := h.ExpenseDB.GetExpenseByID(r.Context(), user.ID, expenseID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	// If-Match не обов'язковий для повернення, але якщо переданий - перевіряється
+	if r.Header.Get("If-Match") != "" {
+		if problem := checkIfMatch(r, previousExpense.Version); problem != nil {
+			writeProblem(w, r, problem)
+			return
+		}
+	}
+
+	target.ID = expenseID
+	target.UserID = user.ID
+	target.Version = previousExpense.Version
+
+	audit := auditOf(r, user.ID)
+	audit.Action = models.ActionRevert
+
+	err = h.ExpenseDB.UpdateUserExpenses(r.Context(), user.ID, target, audit)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	target.Version++
+
+	writeJSONWithETag(w, r, target, versionETag(target.Version))
+}
+
+// auditOf описує зміну витрати, яку користувач actorID робить у межах запиту r;
+// сховище записує її в історію разом із самою зміною
+func auditOf(r *http.Request, actorID int) db.Audit {
+	return db.Audit{ActorID: actorID, RequestID: middleware.RequestIDFromRequest(r)}
+}
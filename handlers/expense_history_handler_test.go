@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// ---------------- HISTORY TESTS --------------------
+func TestExpensesHandler_GetHistory(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", fmt.Sprintf("/expenses/%d/history", trashedExpenseID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	var history []models.ExpenseRevision
+	err = json.Unmarshal(rr.Body.Bytes(), &history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Створення та переміщення в кошик
+	if len(history) != 2 {
+		t.Errorf("Отримано некоректну кількість ревізій: отримано %d, очікувалося %d",
+			len(history), 2)
+	}
+}
+
+func TestExpensesHandler_GetHistory_NotFound(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/99/history", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_GetHistory_ServerError(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/1/history", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+	handler.HistoryDB = &failingExpenseHistoryDB{err: errServer}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusInternalServerError)
+	}
+}
+
+func TestExpensesHandler_GetHistory_IncorrectTokenInRequest(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/1/history", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Incorrect")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnauthorized)
+	}
+}
+
+func TestExpensesHandler_RevertExpense(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/expenses/1/history/1/revert", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("X-Request-ID", "revert-request")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	history := expenseHistory(t, handler, 1)
+	if len(history) != 2 {
+		t.Fatalf("Отримано некоректну кількість ревізій: отримано %d, очікувалося %d",
+			len(history), 2)
+	}
+
+	revision := history[1]
+	if revision.Action != models.ActionRevert || revision.RequestID != "revert-request" || revision.ActorID != 1 {
+		t.Errorf("Записано некоректну ревізію: %+v", revision)
+	}
+}
+
+func TestExpensesHandler_RevertExpense_ToDeletedState(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+
+	// Остання ревізія витрати з кошика - її видалення
+	history := expenseHistory(t, handler, trashedExpenseID)
+	url := fmt.Sprintf("/expenses/%d/history/%d/revert", trashedExpenseID, history[len(history)-1].ID)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusConflict)
+	}
+}
+
+func TestExpensesHandler_RevertExpense_UnknownRevision(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/expenses/1/history/99/revert", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_RevertExpense_NotAllowed(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/1/history/1/revert", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusMethodNotAllowed)
+	}
+}
+
+// -------------- END HISTORY TESTS --------------
+
+// -------------- REVISION RECORDING TESTS --------------
+func TestExpensesHandler_PostExpense_RecordsRevision(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"amount": 10, "category": "test"}`)
+	req, err := http.NewRequest("POST", "/expenses", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	expenses := userExpenses(t, handler)
+	created := expenses[len(expenses)-1]
+
+	history := expenseHistory(t, handler, created.ID)
+	if len(history) != 1 {
+		t.Fatalf("Отримано некоректну кількість ревізій: отримано %d, очікувалося %d",
+			len(history), 1)
+	}
+
+	revision := history[0]
+	if revision.Action != models.ActionCreate || revision.ActorID != 1 {
+		t.Errorf("Записано некоректну ревізію: %+v", revision)
+	}
+
+	if revision.RequestID == "" {
+		t.Errorf("Ревізію записано без ідентифікатора запиту")
+	}
+}
+
+func TestExpensesHandler_DeleteExpense_RecordsRevision(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	history := expenseHistory(t, handler, 1)
+	if len(history) != 2 {
+		t.Fatalf("Отримано некоректну кількість ревізій: отримано %d, очікувалося %d",
+			len(history), 2)
+	}
+
+	revision := history[1]
+	if revision.Action != models.ActionDelete || revision.ActorID != 1 {
+		t.Errorf("Записано некоректну ревізію: %+v", revision)
+	}
+}
+
+func TestExpensesHandler_PutExpense_ForeignExpense(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+
+	// Витрата іншого користувача (ID 2)
+	err := handler.UserDB.AddUser(t.Context(), models.User{Username: "Jane Doe", Password: "12345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreignID, err := handler.ExpenseDB.AddExpense(t.Context(),
+		models.Expense{UserID: 2, Amount: 1, Category: "food", Date: fixedTime}, db.Audit{ActorID: 2, RequestID: "fixture"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expenseJSON := fmt.Sprintf(`{"id": %d, "rawdate": "2023-05-27", "category": "food", "amount": 1}`, foreignID)
+	req, err := http.NewRequest("PUT", "/expenses", bytes.NewBufferString(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+// -------------- END REVISION RECORDING TESTS --------------
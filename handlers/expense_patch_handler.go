@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// detailInvalidPatch - опис проблеми, коли документ JSON Merge Patch не можна застосувати до витрати
+const detailInvalidPatch = "invalid merge patch"
+
+// patchExpense обробляє PATCH /expenses/{id} у форматі JSON Merge Patch (RFC 7396):
+// змінюються лише передані поля, решта залишається без змін.
+func (h *ExpenseHandler) patchExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "" && !strings.HasPrefix(contentType, "application/merge-patch+json") &&
+		!strings.HasPrefix(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, "use application/merge-patch+json or application/json")
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if problem := decodeJSON(w, r, &patch, maxBodyBytes, "request body must be a JSON object"); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+
+	// Попередній стан витрати (заодно перевіряється, що витрата належить користувачу)
+	previousExpense, err := h.ExpenseDB.GetExpenseByID(r.Context(), existingUser.ID, expenseID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	// Клієнт має підтвердити, що змінює актуальну версію (If-Match)
+	if problem := checkIfMatch(r, previousExpense.Version); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+
+	patchedExpense := previousExpense
+	err = applyMergePatch(&patchedExpense, patch)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	if problem := validate(detailInvalidPatch, patchedExpense); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+
+	err = h.ExpenseDB.UpdateUserExpenses(r.Context(), existingUser.ID, patchedExpense, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	patchedExpense.Version++
+
+	writeJSONWithETag(w, r, patchedExpense, versionETag(patchedExpense.Version))
+}
+
+// applyMergePatch застосовує до витрати передані поля.
+// Поля id, user_id та version змінювати не можна; null для обов'язкових полів неприпустимий.
+// Усі некоректні поля повертаються разом у ValidationProblem.
+func applyMergePatch(expense *models.Expense, patch map[string]json.RawMessage) error {
+	var fieldErrs []FieldError
+	for field, value := range patch {
+		if string(value) == "null" {
+			fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "must not be null"})
+			continue
+		}
+
+		var err error
+		switch field {
+		case "category":
+			err = json.Unmarshal(value, &expense.Category)
+		case "amount":
+			err = json.Unmarshal(value, &expense.Amount)
+		case "date":
+			err = json.Unmarshal(value, &expense.Date)
+		case "rawdate":
+			var rawDate string
+			err = json.Unmarshal(value, &rawDate)
+			if err == nil {
+				expense.Date, err = time.Parse(validation.DateLayout, rawDate)
+			}
+		default:
+			fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "cannot be changed"})
+			continue
+		}
+
+		if err != nil {
+			fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "has an invalid value"})
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		sort.Slice(fieldErrs, func(i, j int) bool { return fieldErrs[i].Field < fieldErrs[j].Field })
+		return ValidationProblem(detailInvalidPatch, fieldErrs...)
+	}
+	return nil
+}
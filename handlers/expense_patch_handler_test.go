@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// patchHeaders - заголовки JSON Merge Patch актуальної версії витрати з SetUpHandlerDep
+var patchHeaders = map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": `"1"`}
+
+// ---------------- PATCH TESTS --------------------
+func TestExpensesHandler_PatchExpense(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"amount": 42}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	var expense models.Expense
+	err := json.Unmarshal(rr.Body.Bytes(), &expense)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expense.Amount != 42 || expense.Category != "test" || expense.Version != 2 {
+		t.Errorf("Отримано некоректну витрату після PATCH: %+v", expense)
+	}
+
+	if etag := rr.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("Отримано некоректний ETag: отримано %v, очікувалося %v", etag, `"2"`)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_RawDate(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"rawdate": "2023-05-27", "category": "food"}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	var expense models.Expense
+	err := json.Unmarshal(rr.Body.Bytes(), &expense)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDate := time.Date(2023, 5, 27, 0, 0, 0, 0, time.UTC)
+	if !expense.Date.Equal(expectedDate) || expense.Category != "food" || expense.Amount != 10 {
+		t.Errorf("Отримано некоректну витрату після PATCH: %+v", expense)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_NotFound(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/99", patchHeaders, `{"amount": 42}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_NullRequiredField(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"category": null}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_ReadOnlyField(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"user_id": 2}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_IncorrectBodyRequest(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"amount": "invalid"}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_PreconditionRequired(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"amount": 42}`)
+	req.Header.Del("If-Match")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusPreconditionRequired {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusPreconditionRequired)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_UnsupportedMediaType(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"amount": 42}`)
+	req.Header.Set("Content-Type", "text/plain")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnsupportedMediaType {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_IncorrectTokenInRequest(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"amount": 42}`)
+	req.Header.Set("Token", "Incorrect")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnauthorized)
+	}
+}
+
+// -------------- END PATCH TESTS --------------
+
+func TestExpensesHandler_PutExpense_PathAndBodyIDMismatch(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 2, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
@@ -1,15 +1,15 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"sort"
-	"strings"
+	"strconv"
 	"time"
 
 	db "github.com/ChomuCake/uni-golang-labs/database"
 	"github.com/ChomuCake/uni-golang-labs/models"
 	"github.com/ChomuCake/uni-golang-labs/util"
+	"github.com/ChomuCake/uni-golang-labs/validation"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -22,204 +22,345 @@ func (a ByDate) Less(i, j int) bool { return a[i].Date.Before(a[j].Date) }
 // DI
 
 type ExpenseHandler struct {
-	ExpenseDB db.ExpenseDB      // Використовуємо загальний інтерфейс роботи з даними ExpenseDB(для витрат)
-	UserDB    db.UserDB         // Використовуємо загальний інтерфейс роботи з даними UserDB(для юзерів)
-	TokenMng  util.TokenManager // Використовуємо загальний інтерфейс роботи з токенами
+	ExpenseDB      db.ExpenseDB        // Використовуємо загальний інтерфейс роботи з даними ExpenseDB(для витрат)
+	HistoryDB      db.ExpenseHistoryDB // Використовуємо загальний інтерфейс роботи з історією змін витрат
+	UserDB         db.UserDB           // Використовуємо загальний інтерфейс роботи з даними UserDB(для юзерів)
+	TokenMng       util.TokenManager   // Використовуємо загальний інтерфейс роботи з токенами
+	IdempotencyDB  db.IdempotencyDB    // Сховище ключів ідемпотентності (nil - заголовок Idempotency-Key ігнорується)
+	IdempotencyTTL time.Duration       // Час зберігання відповіді для ключа ідемпотентності (0 - DefaultIdempotencyTTL)
 }
 
-// Функція ExpensesHandler, яка обробляє запити. У цій функції ми створюємо екземпляр expenseHandler
-// та передаємо йому залежність - екземпляр db.MySQLExpenseDB(конкретна реалізація)
-// та екземпляр db.MySQLUserDB(конкретна реалізація)
-func ExpensesHandler(w http.ResponseWriter, r *http.Request) {
-	handler := &ExpenseHandler{
-		ExpenseDB: &db.MySQLExpenseDB{
-			DB: db.GetDB(),
-		},
-		UserDB: &db.MySQLUserDB{
-			DB: db.GetDB(),
-		},
-		TokenMng: &util.JWTTokenManager{},
-	}
-
-	handler.Handle(w, r)
+// Routes повертає маршрути витрат відносно префікса API. Змінюючі маршрути підтримують Idempotency-Key.
+func (h *ExpenseHandler) Routes() []Route {
+	return []Route{
+		{"GET /expenses", http.HandlerFunc(h.listExpenses)},
+		{"POST /expenses", h.idempotent(h.createExpense)},
+		{"PUT /expenses", h.idempotent(h.updateExpense)}, // ID витрати в тілі запиту
+		{"GET /expenses/{id}", http.HandlerFunc(h.getExpense)},
+		{"PUT /expenses/{id}", h.idempotent(h.updateExpense)},
+		{"PATCH /expenses/{id}", h.idempotent(h.patchExpense)},
+		{"DELETE /expenses/{id}", h.idempotent(h.deleteExpense)},
+		{"POST /expenses/batch", h.idempotent(h.batch)},
+		{"GET /expenses/trash", http.HandlerFunc(h.listTrash)},
+		{"DELETE /expenses/trash/{id}", h.idempotent(h.purgeExpense)},
+		{"POST /expenses/trash/{id}/restore", h.idempotent(h.restoreExpense)},
+		{"GET /expenses/{id}/history", http.HandlerFunc(h.listHistory)},
+		{"POST /expenses/{id}/history/{revision}/revert", h.idempotent(h.revertExpense)},
+	}
 }
 
+// Handle обслуговує маршрути витрат без префікса API (окремо від застосунку, напр. у тестах)
 func (h *ExpenseHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodPost {
-		var expense models.Expense
-		err := json.NewDecoder(r.Body).Decode(&expense)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	NewMux(h.Routes()).ServeHTTP(w, r)
+}
 
-		// Отримання айді користувача з заголовка авторизації
-		userID, err := h.TokenMng.ExtractUserIDFromRequest(r)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+// authenticate повертає користувача з токена запиту. Якщо токен недійсний або користувача немає,
+// відповідь з помилкою вже надіслано і повертається false.
+func (h *ExpenseHandler) authenticate(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	// Отримання айді користувача з заголовка авторизації
+	userID, err := h.TokenMng.ExtractUserIDFromRequest(r)
+	if err != nil {
+		writeError(w, r, http.StatusUnauthorized, detailInvalidToken)
+		return models.User{}, false
+	}
 
-		// Перевірка, чи користувач існує
-		existingUser, err := h.UserDB.GetUserByID(int(userID))
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	// Перевірка, чи користувач існує
+	existingUser, err := h.UserDB.GetUserByID(r.Context(), int(userID))
+	if err != nil {
+		writeUserLookupError(w, r, err)
+		return models.User{}, false
+	}
 
-		expense.Date = time.Now()
-		expense.UserID = existingUser.ID
+	return existingUser, true
+}
 
-		err = h.ExpenseDB.AddExpense(expense)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+// createExpense обробляє POST /expenses - додавання витрати з поточною датою
+func (h *ExpenseHandler) createExpense(w http.ResponseWriter, r *http.Request) {
+	var expense models.Expense
+	if problem := decodeJSON(w, r, &expense, maxBodyBytes, detailInvalidExpense); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+	if problem := validate(detailInvalidExpense, expense); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
 
-		w.WriteHeader(http.StatusCreated)
-	} else if r.Method == http.MethodGet {
-		// Отримання айді користувача з заголовка авторизації
-		userID, err := h.TokenMng.ExtractUserIDFromRequest(r)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
 
-		// Перевірка, чи користувач існує
-		existingUser, err := h.UserDB.GetUserByID(int(userID))
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	expense.Date = time.Now()
+	expense.UserID = existingUser.ID
 
-		userExpenses, err := h.ExpenseDB.GetUserExpenses(existingUser.ID)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+	var err error
+	expense.ID, err = h.ExpenseDB.AddExpense(r.Context(), expense, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
 
-		sortExpensesBy := r.URL.Query().Get("sort")
+	w.Header().Set("Location", APIPrefix+"/expenses/"+strconv.Itoa(expense.ID))
+	w.WriteHeader(http.StatusCreated)
+}
 
-		switch sortExpensesBy {
-		case "day":
-			today := time.Now().Truncate(24 * time.Hour) // Отримуємо поточну дату без часу
-			var todayExpenses []models.Expense
+// listExpenses обробляє GET /expenses - перелік витрат користувача.
+// Параметр sort: day - лише сьогоднішні, month - за поточний місяць, all або без параметра - усі за датою.
+// Параметри category, from та to звужують перелік, limit та offset вибирають сторінку;
+// кількість витрат без урахування сторінки повертається в заголовку X-Total-Count.
+func (h *ExpenseHandler) listExpenses(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
 
-			// Фільтруємо витрати за сьогоднішній день
-			for _, expense := range userExpenses {
-				if expense.Date.Year() == today.Year() &&
-					expense.Date.Month() == today.Month() &&
-					expense.Date.Day() == today.Day() {
-					todayExpenses = append(todayExpenses, expense)
-				}
-			}
-			userExpenses = todayExpenses
+	filter, problem := parseExpenseFilter(r.URL.Query())
+	if problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
 
-		case "month":
-			month := time.Now().Month() // Поточний місяць
-			var monthExpenses []models.Expense
+	userExpenses, err := h.ExpenseDB.GetUserExpenses(r.Context(), existingUser.ID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
 
-			// Фільтруємо витрати за поточний місяць
-			for _, expense := range userExpenses {
-				if expense.Date.Month() == month {
-					monthExpenses = append(monthExpenses, expense)
-				}
-			}
-			userExpenses = monthExpenses
-
-		case "all":
-			sort.SliceStable(userExpenses, func(i, j int) bool {
-				return userExpenses[i].Date.Before(userExpenses[j].Date)
-			})
-		default:
-			if sortExpensesBy != "" {
-				w.WriteHeader(http.StatusMisdirectedRequest)
-				return
-			}
+	sortExpensesBy := r.URL.Query().Get("sort")
 
-			sort.SliceStable(userExpenses, func(i, j int) bool {
-				return userExpenses[i].Date.Before(userExpenses[j].Date)
-			})
+	switch sortExpensesBy {
+	case "day":
+		today := time.Now().Truncate(24 * time.Hour) // Отримуємо поточну дату без часу
+		var todayExpenses []models.Expense
+
+		// Фільтруємо витрати за сьогоднішній день
+		for _, expense := range userExpenses {
+			if expense.Date.Year() == today.Year() &&
+				expense.Date.Month() == today.Month() &&
+				expense.Date.Day() == today.Day() {
+				todayExpenses = append(todayExpenses, expense)
+			}
 		}
+		userExpenses = todayExpenses
 
-		w.Header().Set("Content-Type", "application/json")
-		err = json.NewEncoder(w).Encode(userExpenses)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+	case "month":
+		month := time.Now().Month() // Поточний місяць
+		var monthExpenses []models.Expense
+
+		// Фільтруємо витрати за поточний місяць
+		for _, expense := range userExpenses {
+			if expense.Date.Month() == month {
+				monthExpenses = append(monthExpenses, expense)
+			}
 		}
-	} else if r.Method == http.MethodPut {
-		// Отримання айді користувача з заголовка авторизації
-		userID, err := h.TokenMng.ExtractUserIDFromRequest(r)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+		userExpenses = monthExpenses
+
+	case "all":
+		sort.SliceStable(userExpenses, func(i, j int) bool {
+			return userExpenses[i].Date.Before(userExpenses[j].Date)
+		})
+	default:
+		if sortExpensesBy != "" {
+			writeError(w, r, http.StatusMisdirectedRequest, "sort must be one of day, month or all")
 			return
 		}
 
-		// Перевірка, чи користувач існує
-		_, err = h.UserDB.GetUserByID(int(userID))
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+		sort.SliceStable(userExpenses, func(i, j int) bool {
+			return userExpenses[i].Date.Before(userExpenses[j].Date)
+		})
+	}
 
-		var updatedExpense models.Expense
-		err = json.NewDecoder(r.Body).Decode(&updatedExpense)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	userExpenses, total := filter.apply(userExpenses)
+	if userExpenses == nil {
+		userExpenses = []models.Expense{} // Порожній перелік - [], а не null
+	}
+	w.Header().Set(totalCountHeader, strconv.Itoa(total))
+	writeJSONWithETag(w, r, userExpenses, "")
+}
 
-		// Парсинг рядкового значення дати
-		parsedDate, err := time.Parse("2006-01-02", updatedExpense.RawDate)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+// updateExpense обробляє PUT /expenses/{id} (та PUT /expenses з ID у тілі) - повна заміна витрати
+func (h *ExpenseHandler) updateExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
 
-		// Оновлення поля Date
-		updatedExpense.Date = parsedDate
+	var updatedExpense models.Expense
+	if problem := decodeJSON(w, r, &updatedExpense, maxBodyBytes, detailInvalidExpense); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
 
-		// Оновлення витрати
-		err = h.ExpenseDB.UpdateUserExpenses(updatedExpense)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+	// ID зі шляху має пріоритет і не може суперечити ID у тілі
+	if r.PathValue("id") != "" {
+		pathID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || (updatedExpense.ID != 0 && updatedExpense.ID != pathID) {
+			writeError(w, r, http.StatusBadRequest, "expense id in the path must be a number matching the id in the body")
 			return
 		}
+		updatedExpense.ID = pathID
+	}
 
-		w.WriteHeader(http.StatusOK)
-	} else if r.Method == http.MethodDelete {
-		// Отримання айді користувача з заголовка авторизації
-		userID, err := h.TokenMng.ExtractUserIDFromRequest(r)
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	// PUT замінює витрату повністю, тому дата обов'язкова
+	fieldErrs := validation.Struct(updatedExpense)
+	if updatedExpense.RawDate == "" {
+		fieldErrs = append(fieldErrs, FieldError{Field: "rawdate", Message: "is required"})
+	}
+	if len(fieldErrs) > 0 {
+		writeProblem(w, r, ValidationProblem(detailInvalidExpense, fieldErrs...))
+		return
+	}
 
-		// Перевірка, чи користувач існує
-		_, err = h.UserDB.GetUserByID(int(userID))
-		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	// Парсинг рядкового значення дати (формат уже перевірено)
+	parsedDate, err := time.Parse(validation.DateLayout, updatedExpense.RawDate)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
 
-		// Розбиття URL шляху для отримання ID витрати
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) != 3 {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		expenseID := pathParts[2]
+	// Оновлення поля Date
+	updatedExpense.Date = parsedDate
+	updatedExpense.UserID = existingUser.ID
 
-		err = h.ExpenseDB.DeleteExpense(expenseID)
-		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
+	// Попередній стан витрати (заодно перевіряється, що витрата належить користувачу)
+	previousExpense, err := h.ExpenseDB.GetExpenseByID(r.Context(), existingUser.ID, updatedExpense.ID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	// Клієнт має підтвердити, що змінює актуальну версію (If-Match)
+	if problem := checkIfMatch(r, previousExpense.Version); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+	updatedExpense.Version = previousExpense.Version
+
+	// Оновлення витрати
+	err = h.ExpenseDB.UpdateUserExpenses(r.Context(), existingUser.ID, updatedExpense, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	updatedExpense.Version++
+
+	w.Header().Set("ETag", versionETag(updatedExpense.Version))
+	w.WriteHeader(http.StatusOK)
+}
+
+// deleteExpense обробляє DELETE /expenses/{id}: витрата не видаляється остаточно, а переміщується в кошик
+func (h *ExpenseHandler) deleteExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	// Попередній стан витрати (заодно перевіряється, що витрата належить користувачу)
+	previousExpense, err := h.ExpenseDB.GetExpenseByID(r.Context(), existingUser.ID, expenseID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	// Клієнт має підтвердити, що видаляє актуальну версію (If-Match)
+	if problem := checkIfMatch(r, previousExpense.Version); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+
+	err = h.ExpenseDB.DeleteExpense(r.Context(), existingUser.ID, expenseID, previousExpense.Version, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// listTrash обробляє GET /expenses/trash - перелік видалених витрат
+func (h *ExpenseHandler) listTrash(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	trash, err := h.ExpenseDB.GetUserTrash(r.Context(), existingUser.ID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	if trash == nil {
+		trash = []models.Expense{}
+	}
+	writeJSONWithETag(w, r, trash, "")
+}
+
+// purgeExpense обробляє DELETE /expenses/trash/{id} - остаточне видалення витрати з кошика
+func (h *ExpenseHandler) purgeExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := h.ExpenseDB.PurgeExpense(r.Context(), existingUser.ID, expenseID, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// restoreExpense обробляє POST /expenses/trash/{id}/restore - повернення витрати з кошика
+func (h *ExpenseHandler) restoreExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := h.ExpenseDB.RestoreExpense(r.Context(), existingUser.ID, expenseID, auditOf(r, existingUser.ID))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getExpense обробляє GET /expenses/{id} - окрема витрата разом з ETag її версії
+func (h *ExpenseHandler) getExpense(w http.ResponseWriter, r *http.Request) {
+	existingUser, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	expenseID, ok := pathInt(w, r, "id")
+	if !ok {
+		return
+	}
 
-		w.WriteHeader(http.StatusOK)
-	} else {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	expense, err := h.ExpenseDB.GetExpenseByID(r.Context(), existingUser.ID, expenseID)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
 	}
 
+	writeJSONWithETag(w, r, expense, versionETag(expense.Version))
 }
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// ---------------- IN-MEMORY STORAGE TESTS --------------------
+func TestExpensesHandler_MemoryStorage_Lifecycle(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.Handle(rr, req)
+		return rr
+	}
+
+	// Act & Assert: створення (повтор з тим самим ключем не створює другу витрату)
+	var location string
+	for i := 0; i < 2; i++ {
+		rr := serve(newRequest(t, "POST", "/expenses", map[string]string{"Idempotency-Key": "create-1"},
+			`{"category": "food", "amount": 10}`))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Отримано некоректний статус-код створення: отримано %v, очікувалося %v", rr.Code, http.StatusCreated)
+		}
+		location = rr.Header().Get("Location")
+	}
+	expenseURL := strings.TrimPrefix(location, APIPrefix)
+	expenseID := path.Base(expenseURL)
+
+	rr := serve(newRequest(t, "GET", "/expenses?sort=all", nil, ""))
+	var expenses []models.Expense
+	if err := json.Unmarshal(rr.Body.Bytes(), &expenses); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || len(expenses) != 5 || expenses[4].Version != 1 {
+		t.Fatalf("Отримано некоректний список витрат: %v %+v", rr.Code, expenses)
+	}
+
+	// Оновлення з актуальною версією, потім повторне - із застарілою
+	update := `{"category": "rent", "amount": 20, "rawdate": "2023-05-27"}`
+	rr = serve(newRequest(t, "PUT", expenseURL, map[string]string{"If-Match": `"1"`}, update))
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"2"` {
+		t.Errorf("Отримано некоректну відповідь на оновлення: %v, ETag %q", rr.Code, rr.Header().Get("ETag"))
+	}
+
+	rr = serve(newRequest(t, "PUT", expenseURL, map[string]string{"If-Match": `"1"`}, update))
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", rr.Code, http.StatusPreconditionFailed)
+	}
+
+	// Видалення в кошик та відновлення
+	rr = serve(newRequest(t, "DELETE", expenseURL, map[string]string{"If-Match": `"2"`}, ""))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код видалення: отримано %v, очікувалося %v", rr.Code, http.StatusOK)
+	}
+
+	rr = serve(newRequest(t, "GET", expenseURL, nil, ""))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Видалена витрата доступна: отримано %v, очікувалося %v", rr.Code, http.StatusNotFound)
+	}
+
+	rr = serve(newRequest(t, "POST", "/expenses/trash/"+expenseID+"/restore", nil, ""))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код відновлення: отримано %v, очікувалося %v", rr.Code, http.StatusOK)
+	}
+
+	// Історія: створення, оновлення, видалення, відновлення
+	rr = serve(newRequest(t, "GET", expenseURL+"/history", nil, ""))
+	var history []models.ExpenseRevision
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedActions := []string{models.ActionCreate, models.ActionUpdate, models.ActionDelete, models.ActionRestore}
+	if len(history) != len(expectedActions) {
+		t.Fatalf("Отримано некоректну історію: %+v", history)
+	}
+	for i, revision := range history {
+		if revision.Action != expectedActions[i] {
+			t.Errorf("Отримано некоректну дію %d: отримано %v, очікувалося %v", i, revision.Action, expectedActions[i])
+		}
+	}
+}
+
+// -------------- END IN-MEMORY STORAGE TESTS --------------
@@ -2,69 +2,36 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql" // only for sql.ErrNoRows
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ChomuCake/uni-golang-labs/database"
 	"github.com/ChomuCake/uni-golang-labs/models"
 	"github.com/golang-jwt/jwt"
 )
 
-// MockExpenseDB є замінником реалізації ExpenseDB
-type MockExpenseDB struct{}
-
-func (db *MockExpenseDB) AddExpense(expense models.Expense) error {
-	if expense.RawDate == "err" {
-		return errors.New("server error")
-	}
-	return nil
-}
-
-func (db *MockExpenseDB) GetUserExpenses(userID int) ([]models.Expense, error) {
-	if userID == 3 {
-		return nil, errors.New("server error")
-	}
-	return []models.Expense{
-		{ID: 1, Amount: 10, Date: fixedTime, Category: "test", UserID: 1},
-		{ID: 2, Amount: 20, Date: fixedTime, Category: "test", UserID: 1},                    //day
-		{ID: 3, Amount: 20, Date: fixedTime.AddDate(0, 0, -1), Category: "test", UserID: 1},  //month
-		{ID: 4, Amount: 20, Date: fixedTime.AddDate(0, 0, -32), Category: "test", UserID: 1}, // all
-	}, nil
-}
-
-func (db *MockExpenseDB) UpdateUserExpenses(expense models.Expense) error {
-	if expense.Amount == -1 {
-		return errors.New("server error")
-	}
-	return nil
-}
-
-func (db *MockExpenseDB) DeleteExpense(expenseID string) error {
-	if expenseID == "99" {
-		return errors.New("not found")
-	}
-	return nil
-}
-
 // MockUserDB є замінником реалізації UserDB
 type MockUserDB struct{}
 
-func (db *MockUserDB) GetUserByID(userID int) (models.User, error) {
+func (db *MockUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
 	if userID == 1 {
 		return models.User{ID: 1, Username: "John Doe"}, nil
 	}
 	if userID == 3 {
 		return models.User{ID: 3, Username: "Joe Doe"}, nil
 	}
-	return models.User{}, errors.New("server error")
-
+	return models.User{}, database.ErrNotFound
 }
 
-func (db *MockUserDB) AddUser(user models.User) error {
+func (db *MockUserDB) AddUser(ctx context.Context, user models.User) error {
 	if user.Username == "ErrNoRows" {
 		return sql.ErrNoRows
 	}
@@ -75,7 +42,7 @@ func (db *MockUserDB) AddUser(user models.User) error {
 	return nil
 }
 
-func (db *MockUserDB) GetUserByUsername(username string) (models.User, error) {
+func (db *MockUserDB) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
 	if username == "ErrNoRows" {
 		return models.User{}, errors.New("server error")
 	}
@@ -91,7 +58,7 @@ func (db *MockUserDB) GetUserByUsername(username string) (models.User, error) {
 	return models.User{ID: 1, Username: "John Doe"}, nil
 }
 
-func (db *MockUserDB) GetUserByUsernameAndPassword(username, password string) (models.User, error) {
+func (db *MockUserDB) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (models.User, error) {
 	if username == "ErrNoRows" {
 		return models.User{}, sql.ErrNoRows
 	}
@@ -152,20 +119,177 @@ func (tm *MockTokenManager) ExtractUserIDFromRequest(r *http.Request) (int, erro
 		return 2, nil
 	}
 
-	if token == "TokenWithID3InDB" {
-		return 3, nil
+	return 1, nil
+}
+
+// failingExpenseDB повертає помилку err на будь-яку операцію з витратами
+type failingExpenseDB struct {
+	err error
+}
+
+func (db *failingExpenseDB) AddExpense(ctx context.Context, expense models.Expense, audit database.Audit) (int, error) {
+	return 0, db.err
+}
+
+func (db *failingExpenseDB) GetExpenseByID(ctx context.Context, userID, expenseID int) (models.Expense, error) {
+	return models.Expense{}, db.err
+}
+
+func (db *failingExpenseDB) GetUserExpenses(ctx context.Context, userID int) ([]models.Expense, error) {
+	return nil, db.err
+}
+
+func (db *failingExpenseDB) UpdateUserExpenses(ctx context.Context, userID int, expense models.Expense, audit database.Audit) error {
+	return db.err
+}
+
+func (db *failingExpenseDB) DeleteExpense(ctx context.Context, userID, expenseID, version int, audit database.Audit) error {
+	return db.err
+}
+
+func (db *failingExpenseDB) ApplyBatch(ctx context.Context, userID int, ops []models.BatchOperation, atomic bool, audit database.Audit) ([]database.BatchResult, error) {
+	return nil, db.err
+}
+
+func (db *failingExpenseDB) GetUserTrash(ctx context.Context, userID int) ([]models.Expense, error) {
+	return nil, db.err
+}
+
+func (db *failingExpenseDB) RestoreExpense(ctx context.Context, userID, expenseID int, audit database.Audit) error {
+	return db.err
+}
+
+func (db *failingExpenseDB) PurgeExpense(ctx context.Context, userID, expenseID int, audit database.Audit) error {
+	return db.err
+}
+
+func (db *failingExpenseDB) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return 0, db.err
+}
+
+// failingExpenseHistoryDB повертає помилку err на читання історії
+type failingExpenseHistoryDB struct {
+	err error
+}
+
+func (db *failingExpenseHistoryDB) GetExpenseHistory(ctx context.Context, userID, expenseID int) ([]models.ExpenseRevision, error) {
+	return nil, db.err
+}
+
+func (db *failingExpenseHistoryDB) GetRevision(ctx context.Context, userID, expenseID, revisionID int) (models.ExpenseRevision, error) {
+	return models.ExpenseRevision{}, db.err
+}
+
+// failingIdempotencyDB повертає помилку err на будь-яку операцію з ключами ідемпотентності
+type failingIdempotencyDB struct {
+	err error
+}
+
+func (db *failingIdempotencyDB) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	return models.IdempotencyRecord{}, false, db.err
+}
+
+func (db *failingIdempotencyDB) CompleteIdempotencyKey(ctx context.Context, record models.IdempotencyRecord) error {
+	return db.err
+}
+
+func (db *failingIdempotencyDB) ReleaseIdempotencyKey(ctx context.Context, userID int, key string) error {
+	return db.err
+}
+
+func (db *failingIdempotencyDB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	return 0, db.err
+}
+
+// failingUserDB повертає помилку err на пошук користувача
+type failingUserDB struct {
+	MockUserDB
+	err error
+}
+
+func (db *failingUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	return models.User{}, db.err
+}
+
+// errServer - збій сховища, на який обробник має відповісти 500
+var errServer = errors.New("server error")
+
+// trashedExpenseID - витрата, яку SetUpHandlerDep переміщує в кошик
+const trashedExpenseID = 5
+
+// SetUpHandlerDep створює обробник зі сховищами в пам'яті, де є користувач "John Doe" (ID 1) з витратами
+// категорії "test" версії 1: 1 та 2 - сьогоднішні, 3 - вчорашня, 4 - 32-денної давнини, 5 - у кошику
+func SetUpHandlerDep(t *testing.T) *ExpenseHandler {
+	t.Helper()
+	SetTimeNow()
+
+	ctx := t.Context()
+	storage := database.NewMemoryStorage()
+
+	err := storage.Users.AddUser(ctx, models.User{Username: "John Doe", Password: "12345678"})
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	return 1, nil
+	audit := database.Audit{ActorID: 1, RequestID: "fixture"}
+	for _, expense := range []models.Expense{
+		{Amount: 10, Date: fixedTime},
+		{Amount: 20, Date: fixedTime},
+		{Amount: 20, Date: fixedTime.AddDate(0, 0, -1)},
+		{Amount: 20, Date: fixedTime.AddDate(0, 0, -32)},
+		{Amount: 30, Date: fixedTime},
+	} {
+		expense.UserID, expense.Category = 1, "test"
+		if _, err := storage.Expenses.AddExpense(ctx, expense, audit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := storage.Expenses.DeleteExpense(ctx, 1, trashedExpenseID, 1, audit); err != nil {
+		t.Fatal(err)
+	}
+
+	return &ExpenseHandler{
+		ExpenseDB:     storage.Expenses,
+		HistoryDB:     storage.History,
+		UserDB:        storage.Users,
+		TokenMng:      &MockTokenManager{},
+		IdempotencyDB: storage.Idempotency,
+	}
 }
 
-func SetUpHandlerDep() *ExpenseHandler {
-	h := &ExpenseHandler{
-		ExpenseDB: &MockExpenseDB{},
-		UserDB:    &MockUserDB{},
-		TokenMng:  &MockTokenManager{},
+// newRequest створює запит користувача з ID 1 з JSON-тілом body; headers доповнюють або замінюють типові заголовки
+func newRequest(t *testing.T, method, url string, headers map[string]string, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", "Correct")
+	for name, value := range headers {
+		req.Header.Set(name, value)
 	}
-	return h
+	return req
+}
+
+// expenseHistory читає зі сховища обробника ревізії витрати expenseID користувача з ID 1
+func expenseHistory(t *testing.T, handler *ExpenseHandler, expenseID int) []models.ExpenseRevision {
+	t.Helper()
+	history, err := handler.HistoryDB.GetExpenseHistory(t.Context(), 1, expenseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return history
+}
+
+// userExpenses читає зі сховища обробника активні витрати користувача з ID 1
+func userExpenses(t *testing.T, handler *ExpenseHandler) []models.Expense {
+	t.Helper()
+	expenses, err := handler.ExpenseDB.GetUserExpenses(t.Context(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return expenses
 }
 
 var fixedTime time.Time
@@ -177,7 +301,7 @@ func SetTimeNow() {
 // ---------------- POST TESTS --------------------
 func TestExpensesHandler_PostExpense(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"amount": 10}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("POST", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -185,7 +309,7 @@ func TestExpensesHandler_PostExpense(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Correct")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -201,7 +325,7 @@ func TestExpensesHandler_PostExpense(t *testing.T) {
 
 func TestExpensesHandler_PostExpense_IncorrectTokenInRequest(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"amount": 10}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("POST", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -209,7 +333,7 @@ func TestExpensesHandler_PostExpense_IncorrectTokenInRequest(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Incorrect")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -232,7 +356,7 @@ func TestExpensesHandler_PostExpense_IncorrectBodyRequest(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -248,7 +372,7 @@ func TestExpensesHandler_PostExpense_IncorrectBodyRequest(t *testing.T) {
 
 func TestExpensesHandler_PostExpense_IncorrectUserIdInRequest(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"amount": 10}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("POST", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -256,7 +380,7 @@ func TestExpensesHandler_PostExpense_IncorrectUserIdInRequest(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "TokenWithoutUserInDB")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -272,14 +396,15 @@ func TestExpensesHandler_PostExpense_IncorrectUserIdInRequest(t *testing.T) {
 
 func TestExpensesHandler_PostExpense_ServerError(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"rawdate": "err"}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("POST", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
 
 	rr := httptest.NewRecorder()
 
@@ -303,9 +428,8 @@ func TestExpensesHandler_GetExpensesDay(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set("Token", "Correct")
-	SetTimeNow()
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -337,9 +461,8 @@ func TestExpensesHandler_GetExpensesMonth(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set("Token", "Correct")
-	SetTimeNow()
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -371,9 +494,8 @@ func TestExpensesHandler_GetExpensesAll(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set("Token", "Correct")
-	SetTimeNow()
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -405,9 +527,8 @@ func TestExpensesHandler_GetExpenses(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set("Token", "Correct")
-	SetTimeNow()
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -440,7 +561,7 @@ func TestExpensesHandler_GetExpenses_IncorrectTokenInRequest(t *testing.T) {
 	}
 	req.Header.Set("Token", "Incorrect")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -461,9 +582,8 @@ func TestExpensesHandler_GetExpenses_InvalidSortParameter(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set("Token", "Correct")
-	SetTimeNow()
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -483,9 +603,10 @@ func TestExpensesHandler_GetExpenses_ServerError(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	req.Header.Set("Token", "TokenWithID3InDB")
+	req.Header.Set("Token", "Correct")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
 
 	rr := httptest.NewRecorder()
 
@@ -507,7 +628,7 @@ func TestExpensesHandler_GetExpenses_IncorrectUserIdInRequest(t *testing.T) {
 	}
 	req.Header.Set("Token", "TokenWithoutUserInDB")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -526,15 +647,16 @@ func TestExpensesHandler_GetExpenses_IncorrectUserIdInRequest(t *testing.T) {
 // -------------- PUT TESTS --------------
 func TestExpensesHandler_PutExpense(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"rawdate": "2023-05-27"}`)
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 10}`)
 	req, err := http.NewRequest("PUT", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -550,7 +672,7 @@ func TestExpensesHandler_PutExpense(t *testing.T) {
 
 func TestExpensesHandler_PutExpense_IncorrectTokenInRequest(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"amount": 10}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("PUT", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -558,7 +680,7 @@ func TestExpensesHandler_PutExpense_IncorrectTokenInRequest(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Incorrect")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -581,7 +703,7 @@ func TestExpensesHandler_PutExpense_IncorrectBodyRequest(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -597,7 +719,7 @@ func TestExpensesHandler_PutExpense_IncorrectBodyRequest(t *testing.T) {
 
 func TestExpensesHandler_PutExpense_IncorrectUserIdInRequest(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"amount": 10}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("PUT", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -605,7 +727,7 @@ func TestExpensesHandler_PutExpense_IncorrectUserIdInRequest(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "TokenWithoutUserInDB")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -628,7 +750,7 @@ func TestExpensesHandler_PutExpense_IncorrectDateFormat(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -644,14 +766,16 @@ func TestExpensesHandler_PutExpense_IncorrectDateFormat(t *testing.T) {
 
 func TestExpensesHandler_PutExpense_ServerError(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"rawdate": "2023-05-27","amount": -1}`)
+	expenseJSON := []byte(`{"id": 1, "rawdate": "2023-05-27", "category": "food", "amount": 1}`)
 	req, err := http.NewRequest("PUT", "/expenses", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
 
 	rr := httptest.NewRecorder()
 
@@ -670,7 +794,7 @@ func TestExpensesHandler_PutExpense_ServerError(t *testing.T) {
 // -------------- DELETE TESTS --------------
 func TestExpensesHandler_DeleteExpense_IncorrectTokenInRequest(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"amount": 10}`)
+	expenseJSON := []byte(`{"category": "food", "amount": 10}`)
 	req, err := http.NewRequest("DELETE", "/expenses/1", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -678,7 +802,7 @@ func TestExpensesHandler_DeleteExpense_IncorrectTokenInRequest(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Incorrect")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -694,13 +818,13 @@ func TestExpensesHandler_DeleteExpense_IncorrectTokenInRequest(t *testing.T) {
 
 func TestExpensesHandler_DeleteExpense_IncorrectBodyRequest(t *testing.T) {
 	// Arrange
-	req, err := http.NewRequest("DELETE", "/expenses/1/invalid", nil)
+	req, err := http.NewRequest("DELETE", "/expenses/invalid", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -716,14 +840,14 @@ func TestExpensesHandler_DeleteExpense_IncorrectBodyRequest(t *testing.T) {
 
 func TestExpensesHandler_DeleteExpense_IncorrectUserIdInRequest(t *testing.T) {
 	// Arrange
-	req, err := http.NewRequest("DELETE", "/expenses", nil)
+	req, err := http.NewRequest("DELETE", "/expenses/1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "TokenWithoutUserInDB")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -745,8 +869,9 @@ func TestExpensesHandler_DeleteExpense(t *testing.T) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -769,7 +894,7 @@ func TestExpensesHandler_DeleteExpense_NotFound(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Correct")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
@@ -783,8 +908,291 @@ func TestExpensesHandler_DeleteExpense_NotFound(t *testing.T) {
 	}
 }
 
+func TestExpensesHandler_DeleteExpense_ServerError(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", "/expenses/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusInternalServerError)
+	}
+}
+
 // -------------- END DELETE TESTS --------------
 
+// -------------- TRASH TESTS --------------
+func TestExpensesHandler_GetTrash(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/trash", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+
+	var expenses []models.Expense
+	err = json.Unmarshal(rr.Body.Bytes(), &expenses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expenses) != 1 || expenses[0].DeletedAt == nil {
+		t.Errorf("Отримано некоректний вміст кошика: %v", expenses)
+	}
+}
+
+func TestExpensesHandler_GetTrash_IncorrectTokenInRequest(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/trash", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Incorrect")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnauthorized)
+	}
+}
+
+func TestExpensesHandler_GetTrash_ServerError(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses/trash", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusInternalServerError)
+	}
+}
+
+func TestExpensesHandler_RestoreExpense(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", fmt.Sprintf("/expenses/trash/%d/restore", trashedExpenseID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+}
+
+func TestExpensesHandler_RestoreExpense_NotFound(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/expenses/trash/99/restore", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_RestoreExpense_IncorrectPath(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/expenses/trash/1/invalid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_PurgeExpense(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("/expenses/trash/%d", trashedExpenseID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusOK)
+	}
+}
+
+func TestExpensesHandler_PurgeExpense_NotFound(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", "/expenses/trash/99", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusNotFound)
+	}
+}
+
+func TestExpensesHandler_RestoreExpense_InvalidID(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/expenses/trash/abc/restore", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_PurgeExpense_InvalidID(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("DELETE", "/expenses/trash/abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_Trash_NotAllowed(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/expenses/trash", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusMethodNotAllowed)
+	}
+	if allow := rr.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) {
+		t.Errorf("Заголовок Allow не містить GET: %q", allow)
+	}
+}
+
+// -------------- END TRASH TESTS --------------
+
 // -------------- NOTALLOWEDMETHOD TESTS --------------
 func TestExpensesHandler_UnknownMethodExpense_NotAllowed(t *testing.T) {
 	// Arrange
@@ -795,7 +1203,7 @@ func TestExpensesHandler_UnknownMethodExpense_NotAllowed(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Token", "Correct")
 
-	handler := SetUpHandlerDep()
+	handler := SetUpHandlerDep(t)
 
 	rr := httptest.NewRecorder()
 
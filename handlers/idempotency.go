@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+
+	// DefaultIdempotencyTTL - час зберігання відповіді, якщо ExpenseHandler.IdempotencyTTL не задано
+	DefaultIdempotencyTTL = 24 * time.Hour
+)
+
+// replayedHeaders - заголовки відповіді, що зберігаються та відтворюються при повторі запиту
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotent обгортає змінюючий обробник: запит із заголовком Idempotency-Key виконується не більше одного разу.
+// Без сховища ключів (IdempotencyDB == nil) або без заголовка запит обробляється як звичайний.
+func (h *ExpenseHandler) idempotent(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.IdempotencyDB == nil || r.Header.Get(idempotencyKeyHeader) == "" {
+			next(w, r)
+			return
+		}
+		h.handleIdempotent(w, r, next)
+	})
+}
+
+// handleIdempotent виконує змінюючий запит з заголовком Idempotency-Key:
+// перший запит виконується та його відповідь зберігається, повтори з тим самим ключем і тілом
+// отримують збережену відповідь, повторне використання ключа з іншим запитом повертає 422.
+func (h *ExpenseHandler) handleIdempotent(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLen {
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLen))
+		return
+	}
+
+	// Ключі належать користувачу; без авторизації запит обробляється як звичайний (і отримає 401)
+	userID, err := h.TokenMng.ExtractUserIDFromRequest(r)
+	if err != nil {
+		next(w, r)
+		return
+	}
+
+	// Тіло зберігається в пам'яті для відбитка, тому обмежується найбільшим допустимим розміром (пакет)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes", maxBytesErr.Limit))
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, "failed to read the request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	ttl := h.IdempotencyTTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	now := time.Now().UTC()
+	record := models.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: requestFingerprint(r, body),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	existing, reserved, err := h.IdempotencyDB.ReserveIdempotencyKey(r.Context(), record)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	if !reserved {
+		switch {
+		case existing.Fingerprint != record.Fingerprint:
+			writeError(w, r, http.StatusUnprocessableEntity, "this Idempotency-Key was already used for a different request") // Ключ уже використано для іншого запиту
+		case existing.StatusCode == 0:
+			writeError(w, r, http.StatusConflict, "a request with this Idempotency-Key is still being processed") // Перший запит з цим ключем ще виконується
+		default:
+			replayResponse(w, existing)
+		}
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: w}
+	next(rec, r)
+
+	// Ключ звільняється або зберігається навіть якщо клієнт уже від'єднався
+	detached := context.WithoutCancel(r.Context())
+
+	// Помилки сервера не зберігаються, щоб клієнт міг повторити запит
+	if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+		if err := h.IdempotencyDB.ReleaseIdempotencyKey(detached, userID, key); err != nil {
+			slog.ErrorContext(detached, "idempotency: failed to release key", "key", key, "error", err)
+		}
+		return
+	}
+
+	record.StatusCode = rec.status
+	record.Headers = make(map[string]string)
+	for _, name := range replayedHeaders {
+		if value := rec.Header().Get(name); value != "" {
+			record.Headers[name] = value
+		}
+	}
+	record.Body = rec.body.Bytes()
+
+	if err := h.IdempotencyDB.CompleteIdempotencyKey(detached, record); err != nil {
+		slog.ErrorContext(detached, "idempotency: failed to store response", "key", key, "error", err)
+	}
+}
+
+// requestFingerprint обчислює хеш методу, шляху із параметрами та тіла запиту
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replayResponse(w http.ResponseWriter, record models.IdempotencyRecord) {
+	for name, value := range record.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body)
+}
+
+// responseRecorder передає відповідь клієнту та одночасно запам'ятовує її статус і тіло
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+// idempotencyKey1 - заголовки запиту з ключем ідемпотентності "key-1"
+var idempotencyKey1 = map[string]string{"Idempotency-Key": "key-1"}
+
+// ---------------- IDEMPOTENCY TESTS --------------------
+func TestExpensesHandler_Idempotency_Replay(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+
+	first := httptest.NewRecorder()
+	second := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(first, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`))
+	handler.Handle(second, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`))
+
+	// Assert
+	if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+		t.Fatalf("Отримано некоректні статус-коди: %v та %v, очікувалося %v",
+			first.Code, second.Code, http.StatusCreated)
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Отримано некоректний заголовок Idempotent-Replayed: %q та %q",
+			first.Header().Get("Idempotent-Replayed"), second.Header().Get("Idempotent-Replayed"))
+	}
+	if created := len(userExpenses(t, handler)) - 4; created != 1 {
+		t.Errorf("Витрата створена %d разів, очікувалося 1", created)
+	}
+}
+
+func TestExpensesHandler_Idempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+
+	first := httptest.NewRecorder()
+	second := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(first, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`))
+	handler.Handle(second, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 20}`))
+
+	// Assert
+	if status := second.Code; status != http.StatusUnprocessableEntity {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestExpensesHandler_Idempotency_InProgress(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`)
+
+	handler := SetUpHandlerDep(t)
+
+	// Ключ зарезервовано запитом, що ще виконується
+	_, _, err := handler.IdempotencyDB.ReserveIdempotencyKey(t.Context(), models.IdempotencyRecord{
+		UserID:      1,
+		Key:         "key-1",
+		Fingerprint: requestFingerprint(req, []byte(`{"category": "food", "amount": 10}`)),
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusConflict)
+	}
+}
+
+func TestExpensesHandler_Idempotency_ReleasedOnServerError(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+	expenseDB := handler.ExpenseDB
+	handler.ExpenseDB = &failingExpenseDB{err: errServer}
+
+	failed := httptest.NewRecorder()
+	retried := httptest.NewRecorder()
+
+	// Act: повтор з тим самим ключем після відновлення сховища
+	handler.Handle(failed, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`))
+	handler.ExpenseDB = expenseDB
+	handler.Handle(retried, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`))
+
+	// Assert
+	if status := failed.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusInternalServerError)
+	}
+	if status := retried.Code; status != http.StatusCreated || retried.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Ключ не звільнено після помилки сервера: отримано %v, очікувалося %v",
+			status, http.StatusCreated)
+	}
+}
+
+func TestExpensesHandler_Idempotency_InvalidKey(t *testing.T) {
+	// Arrange
+	headers := map[string]string{"Idempotency-Key": strings.Repeat("k", maxIdempotencyKeyLen+1)}
+	req := newRequest(t, "POST", "/expenses", headers, `{"category": "food", "amount": 10}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_Idempotency_ServerError(t *testing.T) {
+	// Arrange
+	handler := SetUpHandlerDep(t)
+	handler.IdempotencyDB = &failingIdempotencyDB{err: errServer}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`))
+
+	// Assert
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusInternalServerError)
+	}
+}
+
+func TestExpensesHandler_Idempotency_IncorrectTokenInRequest(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses", idempotencyKey1, `{"category": "food", "amount": 10}`)
+	req.Header.Set("Token", "Incorrect")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnauthorized)
+	}
+}
+
+// -------------- END IDEMPOTENCY TESTS --------------
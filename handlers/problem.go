@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	db "github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/middleware"
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// ProblemContentType - тип вмісту відповідей з помилкою (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Типи помилок (поле type). about:blank означає, що помилку описує сам статус-код.
+const (
+	ProblemTypeBlank           = "about:blank"
+	ProblemTypeValidation      = "/problems/validation-error"
+	ProblemTypeVersionConflict = "/problems/version-conflict"
+)
+
+// Пояснення для типових помилок
+const (
+	detailInvalidToken   = "missing or invalid bearer token" // 401 без дійсного токена
+	detailInvalidExpense = "invalid expense"                 // Тіло запиту не є коректною витратою
+)
+
+// FieldError - помилка значення окремого поля запиту
+type FieldError = validation.FieldError
+
+// Problem - опис помилки у форматі RFC 7807 (problem details). Реалізує error, тож його можна
+// повернути з допоміжної функції та передати writeStoreError без втрати статусу й пояснення.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`   // Шлях запиту
+	RequestID string       `json:"request_id,omitempty"` // Див. заголовок X-Request-ID
+	Errors    []FieldError `json:"errors,omitempty"`     // Помилки окремих полів (для ProblemTypeValidation)
+}
+
+// NewProblem створює опис помилки зі статусом status; заголовок - стандартний текст статусу
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ValidationProblem створює помилку 400 з переліком некоректних полів
+func ValidationProblem(detail string, errs ...FieldError) *Problem {
+	return &Problem{
+		Type:   ProblemTypeValidation,
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Errors: errs,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// writeProblem надсилає опис помилки як application/problem+json, доповнюючи його шляхом та ідентифікатором запиту
+func writeProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	body := *p
+	if body.Instance == "" {
+		body.Instance = r.URL.Path
+	}
+	if body.RequestID == "" {
+		body.RequestID = middleware.RequestIDFromRequest(r)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(body.Status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError надсилає помилку зі статусом status та поясненням detail
+func writeError(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblem(w, r, NewProblem(status, detail))
+}
+
+// writeStoreError перетворює помилку сховища на відповідь: *Problem надсилається як є,
+// інші помилки відображаються на статус-код, а непередбачені записуються в журнал і повертають 500
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		writeProblem(w, r, problem)
+		return
+	}
+	if status, ok := contextErrorStatus(err); ok {
+		writeError(w, r, status, contextErrorDetail(status))
+		return
+	}
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "expense not found")
+		return
+	}
+	if errors.Is(err, db.ErrVersionConflict) {
+		writeProblem(w, r, versionConflictProblem())
+		return
+	}
+
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+	writeError(w, r, http.StatusInternalServerError, "")
+}
+
+// writeUserLookupError відповідає на помилку пошуку авторизованого користувача:
+// користувача немає - 401, решта помилок сховища - як у writeStoreError
+func writeUserLookupError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, r, http.StatusUnauthorized, "user from the token does not exist")
+		return
+	}
+	writeStoreError(w, r, err)
+}
+
+// contextErrorStatus повертає статус для операції сховища, перерваної контекстом:
+// перевищено тайм-аут - 504, запит скасовано (клієнт від'єднався або сервер зупиняється) - 503
+func contextErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, true
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, true
+	}
+	return 0, false
+}
+
+func contextErrorDetail(status int) string {
+	if status == http.StatusGatewayTimeout {
+		return "the storage did not respond in time"
+	}
+	return "the request was cancelled before the storage responded"
+}
+
+// versionConflictProblem - помилка 412: клієнт змінює застарілу версію витрати
+func versionConflictProblem() *Problem {
+	return &Problem{
+		Type:   ProblemTypeVersionConflict,
+		Title:  "Version conflict",
+		Status: http.StatusPreconditionFailed,
+		Detail: "the expense has been modified since it was fetched; get the current version and retry",
+	}
+}
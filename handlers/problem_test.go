@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	db "github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/middleware"
+)
+
+// decodeProblem перевіряє Content-Type відповіді та розбирає її тіло як Problem
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) Problem {
+	t.Helper()
+
+	if contentType := rr.Header().Get("Content-Type"); contentType != ProblemContentType {
+		t.Fatalf("Отримано некоректний Content-Type: отримано %v, очікувалося %v", contentType, ProblemContentType)
+	}
+
+	var problem Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	return problem
+}
+
+func TestWriteProblem(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest("GET", "/expenses/7", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	writeProblem(rr, req, ValidationProblem("invalid expense", FieldError{Field: "amount", Message: "must be positive"}))
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+
+	problem := decodeProblem(t, rr)
+	expected := Problem{
+		Type:      ProblemTypeValidation,
+		Title:     "Validation failed",
+		Status:    http.StatusBadRequest,
+		Detail:    "invalid expense",
+		Instance:  "/expenses/7",
+		RequestID: "req-123",
+		Errors:    []FieldError{{Field: "amount", Message: "must be positive"}},
+	}
+	if fmt.Sprint(problem) != fmt.Sprint(expected) {
+		t.Errorf("Отримано некоректну помилку: отримано %+v, очікувалося %+v", problem, expected)
+	}
+}
+
+func TestWriteStoreError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedType   string
+	}{
+		{"problem", NewProblem(http.StatusConflict, "conflict"), http.StatusConflict, ProblemTypeBlank},
+		{"wrapped problem", fmt.Errorf("prepare: %w", ValidationProblem("invalid")), http.StatusBadRequest, ProblemTypeValidation},
+		{"not found", db.ErrNotFound, http.StatusNotFound, ProblemTypeBlank},
+		{"version conflict", db.ErrVersionConflict, http.StatusPreconditionFailed, ProblemTypeVersionConflict},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, ProblemTypeBlank},
+		{"canceled", context.Canceled, http.StatusServiceUnavailable, ProblemTypeBlank},
+		{"unexpected", fmt.Errorf("server error"), http.StatusInternalServerError, ProblemTypeBlank},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest("GET", "/expenses", nil)
+			rr := httptest.NewRecorder()
+
+			// Act
+			writeStoreError(rr, req, tt.err)
+
+			// Assert
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+					status, tt.expectedStatus)
+			}
+
+			problem := decodeProblem(t, rr)
+			if problem.Status != tt.expectedStatus || problem.Type != tt.expectedType {
+				t.Errorf("Отримано некоректну помилку: отримано %v %v, очікувалося %v %v",
+					problem.Status, problem.Type, tt.expectedStatus, tt.expectedType)
+			}
+			if problem.RequestID == "" {
+				t.Error("Помилка не містить ідентифікатора запиту")
+			}
+		})
+	}
+}
+
+func TestExpensesHandler_PatchExpense_FieldErrors(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"user_id": 2, "category": null, "amount": "invalid"}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+
+	problem := decodeProblem(t, rr)
+	var fields []string
+	for _, fieldErr := range problem.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	expected := []string{"amount", "category", "user_id"}
+	if !slices.Equal(fields, expected) {
+		t.Errorf("Отримано некоректні поля з помилками: отримано %v, очікувалося %v", fields, expected)
+	}
+}
+
+func TestExpensesHandler_UnauthorizedProblem(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Incorrect")
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusUnauthorized)
+	}
+
+	problem := decodeProblem(t, rr)
+	if problem.Detail != detailInvalidToken || problem.Instance != "/expenses" {
+		t.Errorf("Отримано некоректну помилку: %+v", problem)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ChomuCake/uni-golang-labs/validation"
+)
+
+// Обмеження розміру тіла запиту: окрема витрата чи користувач займають кілька сотень байтів,
+// пакет містить до maxBatchOperations операцій
+const (
+	maxBodyBytes      = 64 << 10
+	maxBatchBodyBytes = 1 << 20
+)
+
+// decodeJSON розбирає тіло запиту як єдине JSON-значення в v. Тіло, більше за limit байтів,
+// відхиляється з 413, невідомі поля та поля з неправильним типом - з 400 і переліком полів.
+// detail пояснює, що очікувалося в тілі.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any, limit int64, detail string) *Problem {
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		// Після значення не має бути нічого, крім пробілів
+		if _, tokenErr := dec.Token(); tokenErr != io.EOF {
+			err = errors.New("unexpected data after the JSON value")
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return ValidationProblem(detail, FieldError{Field: typeErr.Field, Message: "must be a JSON " + jsonTypeName(typeErr.Type)})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return ValidationProblem(detail, FieldError{Field: field, Message: "unknown field"})
+	}
+	return NewProblem(http.StatusBadRequest, detail)
+}
+
+// jsonTypeName повертає назву типу JSON, в який розбирається значення типу Go
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return t.Kind().String()
+}
+
+// validate перевіряє значення за правилами з тегів validate і повертає помилку 400 з усіма некоректними полями
+func validate(detail string, v any) *Problem {
+	if errs := validation.Struct(v); len(errs) > 0 {
+		return ValidationProblem(detail, errs...)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpensesHandler_PostExpense_Validation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedErrors []FieldError
+	}{
+		{"invalid fields", `{"category": " ", "amount": -1}`, http.StatusBadRequest, []FieldError{
+			{Field: "category", Message: "is required"},
+			{Field: "amount", Message: "must be at least 1"},
+		}},
+		{"unknown field", `{"category": "food", "amount": 10, "currency": "UAH"}`, http.StatusBadRequest, []FieldError{
+			{Field: "currency", Message: "unknown field"},
+		}},
+		{"invalid type", `{"category": "food", "amount": "10"}`, http.StatusBadRequest, []FieldError{
+			{Field: "amount", Message: "must be a JSON number"},
+		}},
+		{"trailing data", `{"category": "food", "amount": 10} {}`, http.StatusBadRequest, nil},
+		{"too large", `{"category": "` + strings.Repeat("a", maxBodyBytes) + `", "amount": 10}`, http.StatusRequestEntityTooLarge, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req, err := http.NewRequest("POST", "/expenses", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Token", "Correct")
+
+			handler := SetUpHandlerDep(t)
+
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.Handle(rr, req)
+
+			// Assert
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+					status, tt.expectedStatus)
+			}
+
+			problem := decodeProblem(t, rr)
+			if !reflect.DeepEqual(problem.Errors, tt.expectedErrors) {
+				t.Errorf("Отримано помилки полів %v, очікувалося %v", problem.Errors, tt.expectedErrors)
+			}
+		})
+	}
+}
+
+func TestExpensesHandler_PutExpense_RequiresDate(t *testing.T) {
+	// Arrange
+	expenseJSON := []byte(`{"id": 1, "category": "food", "amount": 0}`)
+	req, err := http.NewRequest("PUT", "/expenses/1", bytes.NewBuffer(expenseJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+	req.Header.Set("If-Match", `"1"`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+
+	expected := []FieldError{
+		{Field: "amount", Message: "must be at least 1"},
+		{Field: "rawdate", Message: "is required"},
+	}
+	if problem := decodeProblem(t, rr); !reflect.DeepEqual(problem.Errors, expected) {
+		t.Errorf("Отримано помилки полів %v, очікувалося %v", problem.Errors, expected)
+	}
+}
+
+func TestExpensesHandler_PatchExpense_InvalidResult(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "PATCH", "/expenses/1", patchHeaders, `{"amount": 0}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func TestExpensesHandler_Batch_FieldErrors(t *testing.T) {
+	// Arrange
+	req := newRequest(t, "POST", "/expenses/batch", nil, `{"operations": [
+		{"op": "create", "expense": {"category": "food", "amount": 10}},
+		{"op": "create", "expense": {"amount": -1}},
+		{"op": "update", "id": 1, "version": 1, "expense": {"category": "rent", "amount": 20}}
+	]}`)
+
+	handler := SetUpHandlerDep(t)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+
+	expected := []FieldError{
+		{Field: "operations[1].expense.category", Message: "is required"},
+		{Field: "operations[1].expense.amount", Message: "must be at least 1"},
+		{Field: "operations[2].expense.rawdate", Message: "is required for update"},
+	}
+	if problem := decodeProblem(t, rr); !reflect.DeepEqual(problem.Errors, expected) {
+		t.Errorf("Отримано помилки полів %v, очікувалося %v", problem.Errors, expected)
+	}
+}
+
+func TestUserHandler_PostUserReg_Validation(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/register", bytes.NewBufferString(`{"username": "", "password": "123"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := SetUpUserHandlerDep()
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.RegHandle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+
+	expected := []FieldError{
+		{Field: "username", Message: "is required"},
+		{Field: "password", Message: "must be at least 8 characters long"},
+	}
+	if problem := decodeProblem(t, rr); !reflect.DeepEqual(problem.Errors, expected) {
+		t.Errorf("Отримано помилки полів %v, очікувалося %v", problem.Errors, expected)
+	}
+}
+
+func TestUserHandler_PostUserLogin_MissingPassword(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("POST", "/login", bytes.NewBufferString(`{"username": "John Doe"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := SetUpUserHandlerDep()
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.LoginHandle(rr, req)
+
+	// Assert
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
+			status, http.StatusBadRequest)
+	}
+}
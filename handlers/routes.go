@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// APIPrefix - префікс поточної версії REST API; статичний фронтенд обслуговується з кореня
+const APIPrefix = "/api/v1"
+
+// Route - маршрут API: шаблон http.ServeMux у форматі "МЕТОД /шлях/{параметр}" відносно APIPrefix та його обробник.
+// Запит іншим методом на відомий шлях ServeMux відхиляє з 405 і заголовком Allow.
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Method повертає метод HTTP маршруту
+func (r Route) Method() string {
+	method, _, _ := strings.Cut(r.Pattern, " ")
+	return method
+}
+
+// Path повертає шаблон шляху маршруту без методу
+func (r Route) Path() string {
+	_, path, _ := strings.Cut(r.Pattern, " ")
+	return path
+}
+
+// NewMux реєструє маршрути в новому http.ServeMux без префікса
+func NewMux(routes ...[]Route) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, group := range routes {
+		for _, route := range group {
+			mux.Handle(route.Pattern, route.Handler)
+		}
+	}
+	return mux
+}
+
+// pathInt розбирає числовий параметр шляху name (напр. {id}). Якщо це не число,
+// надсилає 400 і повертає false.
+func pathInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		writeProblem(w, r, ValidationProblem("invalid path parameter", FieldError{Field: name, Message: "must be a number"}))
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ---------------- TIMEOUT TESTS --------------------
+
+func TestExpensesHandler_StoreContextErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: http.StatusGatewayTimeout},
+		{name: "canceled", err: context.Canceled, expected: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req, err := http.NewRequest("GET", "/expenses", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Token", "Correct")
+
+			handler := SetUpHandlerDep(t)
+			handler.ExpenseDB = &failingExpenseDB{err: tt.err}
+
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.Handle(rr, req)
+
+			// Assert
+			if status := rr.Code; status != tt.expected {
+				t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", status, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpensesHandler_UserLookupTimeout(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+	handler.UserDB = &failingUserDB{err: context.DeadlineExceeded}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert: недоступне сховище не видається за відсутнього користувача (401)
+	if status := rr.Code; status != http.StatusGatewayTimeout {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", status, http.StatusGatewayTimeout)
+	}
+}
+
+func TestExpensesHandler_UserLookupStoreError(t *testing.T) {
+	// Arrange
+	req, err := http.NewRequest("GET", "/expenses", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Token", "Correct")
+
+	handler := SetUpHandlerDep(t)
+	handler.UserDB = &failingUserDB{err: errors.New("connection refused")}
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	handler.Handle(rr, req)
+
+	// Assert: збій сховища не видається за відсутнього користувача (401)
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v", status, http.StatusInternalServerError)
+	}
+}
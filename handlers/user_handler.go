@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json"
 	"net/http"
 
 	db "github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/logging"
 	"github.com/ChomuCake/uni-golang-labs/models"
 	"github.com/ChomuCake/uni-golang-labs/util"
 	_ "github.com/go-sql-driver/mysql"
@@ -16,93 +16,107 @@ import (
 type UserHandler struct {
 	UserDB   db.UserDB         // Використовуємо загальний інтерфейс роботи з даними UserDB(для юзерів)
 	TokenMng util.TokenManager // Використовуємо загальний інтерфейс роботи з токенами
+	Logins   LoginObserver     // Необов'язковий облік спроб входу (напр. метрики)
 }
 
-func RegisterHandler(w http.ResponseWriter, r *http.Request) {
-	handler := &UserHandler{
-		UserDB: &db.MySQLUserDB{
-			DB: db.GetDB(),
-		},
-	}
-
-	handler.RegHandle(w, r)
+// LoginObserver отримує результат кожної спроби входу: успішної або з невірними обліковими даними
+type LoginObserver interface {
+	ObserveLogin(success bool)
 }
 
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
-	handler := &UserHandler{
-		UserDB: &db.MySQLUserDB{
-			DB: db.GetDB(),
-		},
-		TokenMng: &util.JWTTokenManager{},
+// Routes повертає маршрути користувачів відносно префікса API
+func (h *UserHandler) Routes() []Route {
+	return []Route{
+		{"POST /register", http.HandlerFunc(h.RegHandle)},
+		{"POST /login", http.HandlerFunc(h.LoginHandle)},
 	}
+}
 
-	handler.LoginHandle(w, r)
+// Handle обслуговує маршрути користувачів без префікса API (окремо від застосунку, напр. у тестах)
+func (h *UserHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	NewMux(h.Routes()).ServeHTTP(w, r)
 }
 
-func (h *UserHandler) RegHandle(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+// detailInvalidCredentials - пояснення для некоректного тіла запиту реєстрації чи входу
+const detailInvalidCredentials = "request body must be a JSON object with a valid username and password"
 
+// loginRequest - правила перевірки облікових даних для входу
+type loginRequest struct {
+	ID       int    `json:"id"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (h *UserHandler) observeLogin(success bool) {
+	if h.Logins != nil {
+		h.Logins.ObserveLogin(success)
 	}
+}
 
+// RegHandle обробляє POST /register - реєстрацію нового користувача
+func (h *UserHandler) RegHandle(w http.ResponseWriter, r *http.Request) {
 	var user models.User
-	err := json.NewDecoder(r.Body).Decode(&user)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if problem := decodeJSON(w, r, &user, maxBodyBytes, detailInvalidCredentials); problem != nil {
+		writeProblem(w, r, problem)
+		return
+	}
+	if problem := validate(detailInvalidCredentials, user); problem != nil {
+		writeProblem(w, r, problem)
 		return
 	}
 
-	_, err = h.UserDB.GetUserByUsername(user.Username)
+	_, err := h.UserDB.GetUserByUsername(r.Context(), user.Username)
 	if err == nil {
-		w.Header().Set("X-Error-Message", "User with this name is already registered")
-		w.WriteHeader(http.StatusConflict) // Код 409 - Conflict, якщо користувач вже існує
+		writeError(w, r, http.StatusConflict, "User with this name is already registered") // Код 409 - Conflict, якщо користувач вже існує
 		return
 	}
 
-	err = h.UserDB.AddUser(user)
+	err = h.UserDB.AddUser(r.Context(), user)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusConflict)
+			writeError(w, r, http.StatusConflict, "User with this name is already registered")
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeStoreError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
 
+// LoginHandle обробляє POST /login - вхід; токен повертається в заголовку Authorization
 func (h *UserHandler) LoginHandle(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	var user models.User
+	if problem := decodeJSON(w, r, &user, maxBodyBytes, detailInvalidCredentials); problem != nil {
+		writeProblem(w, r, problem)
 		return
-
 	}
-
-	var user models.User
-	err := json.NewDecoder(r.Body).Decode(&user)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	// Правила реєстрації не застосовуються до входу: достатньо, щоб обидва поля були передані
+	if problem := validate(detailInvalidCredentials, loginRequest(user)); problem != nil {
+		writeProblem(w, r, problem)
 		return
 	}
 
-	existingUser, err := h.UserDB.GetUserByUsernameAndPassword(user.Username, user.Password)
+	existingUser, err := h.UserDB.GetUserByUsernameAndPassword(r.Context(), user.Username, user.Password)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			w.WriteHeader(http.StatusUnauthorized)
+			h.observeLogin(false)
+			writeError(w, r, http.StatusUnauthorized, "invalid username or password")
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeStoreError(w, r, err)
 		return
 	}
 
 	tokenString, err := h.TokenMng.GenerateToken(existingUser)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeStoreError(w, r, err)
 		return
 	}
 
+	h.observeLogin(true)
+	logging.SetUserID(r.Context(), existingUser.ID)
+
 	// Встановлення токена в заголовок відповіді
 	w.Header().Set("Authorization", tokenString)
 	w.WriteHeader(http.StatusOK)
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 )
 
@@ -18,7 +20,7 @@ func SetUpUserHandlerDep() *UserHandler {
 // ---------------- POST TESTS --------------------
 func TestUserHandler_PostUserLogin(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "John Doe"}`)
+	expenseJSON := []byte(`{"username": "John Doe", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/login", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -65,7 +67,7 @@ func TestUserHandler_PostUserLogin_IncorrectBodyRequest(t *testing.T) {
 
 func TestUserHandler_PostUserLogin_IncorrectUser(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "ErrNoRows"}`)
+	expenseJSON := []byte(`{"username": "ErrNoRows", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/login", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -88,7 +90,7 @@ func TestUserHandler_PostUserLogin_IncorrectUser(t *testing.T) {
 
 func TestUserHandler_PostUserLogin_IncorrectUserServerErr(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "ServerError"}`)
+	expenseJSON := []byte(`{"username": "ServerError", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/login", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -111,7 +113,7 @@ func TestUserHandler_PostUserLogin_IncorrectUserServerErr(t *testing.T) {
 
 func TestUserHandler_PostUserLogin_IncorrectTokenInRequest(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "Incorrect"}`)
+	expenseJSON := []byte(`{"username": "Incorrect", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/login", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -134,7 +136,7 @@ func TestUserHandler_PostUserLogin_IncorrectTokenInRequest(t *testing.T) {
 
 func TestUserHandler_PostUserReg(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "Reg"}`)
+	expenseJSON := []byte(`{"username": "Reg", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/register", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -180,7 +182,7 @@ func TestUserHandler_PostUserReg_IncorrectBodyRequest(t *testing.T) {
 
 func TestUserHandler_PostUserReg_IncorrectName(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "John Doe"}`)
+	expenseJSON := []byte(`{"username": "John Doe", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/register", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -200,8 +202,17 @@ func TestUserHandler_PostUserReg_IncorrectName(t *testing.T) {
 			status, http.StatusConflict)
 	}
 
+	if contentType := rr.Header().Get("Content-Type"); contentType != ProblemContentType {
+		t.Errorf("Отримано некоректний Content-Type: отримано %v, очікувалося %v", contentType, ProblemContentType)
+	}
+
+	var problem Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+
 	expectedErrorMessage := "User with this name is already registered"
-	actualErrorMessage := rr.Header().Get("X-Error-Message")
+	actualErrorMessage := problem.Detail
 	if actualErrorMessage != expectedErrorMessage {
 		t.Errorf("Received incorrect error message: received %v, expected %v",
 			actualErrorMessage, expectedErrorMessage)
@@ -210,7 +221,7 @@ func TestUserHandler_PostUserReg_IncorrectName(t *testing.T) {
 
 func TestUserHandler_PostUserReg_ServerError(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "ServerError"}`)
+	expenseJSON := []byte(`{"username": "ServerError", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/register", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -233,7 +244,7 @@ func TestUserHandler_PostUserReg_ServerError(t *testing.T) {
 
 func TestUserHandler_PostUserReg_ErrNoRows(t *testing.T) {
 	// Arrange
-	expenseJSON := []byte(`{"username": "ErrNoRows"}`)
+	expenseJSON := []byte(`{"username": "ErrNoRows", "password": "12345678"}`)
 	req, err := http.NewRequest("POST", "/register", bytes.NewBuffer(expenseJSON))
 	if err != nil {
 		t.Fatal(err)
@@ -271,13 +282,16 @@ func TestUserHandler_UnknownMethodLogin_NotAllowed(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// Act
-	handler.LoginHandle(rr, req)
+	handler.Handle(rr, req)
 
 	// Assert
 	if status := rr.Code; status != http.StatusMethodNotAllowed {
 		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
 			status, http.StatusMethodNotAllowed)
 	}
+	if allow := rr.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Отримано некоректний заголовок Allow: отримано %q, очікувалося POST", allow)
+	}
 }
 
 func TestUserHandler_UnknownMethodReg_NotAllowed(t *testing.T) {
@@ -294,13 +308,55 @@ func TestUserHandler_UnknownMethodReg_NotAllowed(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// Act
-	handler.RegHandle(rr, req)
+	handler.Handle(rr, req)
 
 	// Assert
 	if status := rr.Code; status != http.StatusMethodNotAllowed {
 		t.Errorf("Отримано некоректний статус-код: отримано %v, очікувалося %v",
 			status, http.StatusMethodNotAllowed)
 	}
+	if allow := rr.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Отримано некоректний заголовок Allow: отримано %q, очікувалося POST", allow)
+	}
 }
 
 // -------------- END NOTALLOWEDMETHOD TESTS --------------
+
+// MockLoginObserver запам'ятовує результати спроб входу
+type MockLoginObserver struct {
+	results []bool
+}
+
+func (o *MockLoginObserver) ObserveLogin(success bool) {
+	o.results = append(o.results, success)
+}
+
+func TestUserHandler_PostUserLogin_ObservesResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     []bool
+	}{
+		{name: "success", username: "John Doe", want: []bool{true}},
+		{name: "wrong credentials", username: "ErrNoRows", want: []bool{false}},
+		{name: "server error is not a login failure", username: "ServerError", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest("POST", "/login", bytes.NewBufferString(`{"username": "`+tt.username+`", "password": "12345678"}`))
+			observer := &MockLoginObserver{}
+			handler := SetUpUserHandlerDep()
+			handler.Logins = observer
+
+			// Act
+			handler.LoginHandle(httptest.NewRecorder(), req)
+
+			// Assert
+			if !slices.Equal(observer.results, tt.want) {
+				t.Errorf("Отримано результати входу %v, очікувалося %v", observer.results, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package logging налаштовує структуроване журналювання (log/slog у форматі JSON) та зберігає
+// в контексті запиту його ідентифікатор і ID користувача. Записи, зроблені через методи *Context
+// (напр. slog.ErrorContext(ctx, ...)), автоматично отримують поле request_id, а під час трасування -
+// також trace_id та span_id.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestInfo - відомості про запит, що заповнюються під час його обробки
+type requestInfo struct {
+	id     string
+	userID atomic.Int64 // 0 - користувач невідомий
+}
+
+type contextKey struct{}
+
+// WithRequestID повертає контекст з ідентифікатором запиту id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestInfo{id: id})
+}
+
+// RequestID повертає ідентифікатор запиту з контексту (порожній, якщо його немає)
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// SetUserID запам'ятовує автентифікованого користувача запиту для журналу доступу.
+// Без ідентифікатора запиту в контексті нічого не робить.
+func SetUserID(ctx context.Context, userID int) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.userID.Store(int64(userID))
+	}
+}
+
+// UserID повертає користувача, збереженого SetUserID
+func UserID(ctx context.Context) (int, bool) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		if id := info.userID.Load(); id != 0 {
+			return int(id), true
+		}
+	}
+	return 0, false
+}
+
+// New створює JSON-логер рівня level, що додає request_id з контексту до кожного запису
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// ParseLevel перетворює назву рівня (debug, info, warn, error) на slog.Level
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(name))
+	return level, err
+}
+
+// contextHandler додає до записів поля з контексту
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
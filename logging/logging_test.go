@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Запис журналу не є JSON: %q", buf.String())
+	}
+	return record
+}
+
+func TestNew_AddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo).With("component", "test")
+
+	logger.InfoContext(WithRequestID(t.Context(), "req-1"), "hello")
+
+	record := decode(t, &buf)
+	if record["request_id"] != "req-1" || record["component"] != "test" || record["msg"] != "hello" {
+		t.Errorf("Отримано запис %v, очікувалися request_id=req-1 та component=test", record)
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelWarn)
+
+	logger.Info("skipped")
+
+	if buf.Len() != 0 {
+		t.Errorf("Запис нижче рівня warn не мав потрапити в журнал: %q", buf.String())
+	}
+}
+
+func TestUserID(t *testing.T) {
+	// Без ідентифікатора запиту користувач не запам'ятовується
+	SetUserID(context.Background(), 5)
+	if _, ok := UserID(context.Background()); ok {
+		t.Error("Очікувалося, що користувача немає")
+	}
+
+	ctx := WithRequestID(t.Context(), "req-2")
+	SetUserID(ctx, 7)
+	if userID, ok := UserID(ctx); !ok || userID != 7 {
+		t.Errorf("Отримано користувача %d (%v), очікувався 7", userID, ok)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if level, err := ParseLevel("debug"); err != nil || level != slog.LevelDebug {
+		t.Errorf("Отримано %v (%v), очікувався %v", level, err, slog.LevelDebug)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("Очікувалася помилка для невідомого рівня")
+	}
+}
@@ -1,31 +1,225 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/ChomuCake/uni-golang-labs/app"
+	"github.com/ChomuCake/uni-golang-labs/config"
 	db "github.com/ChomuCake/uni-golang-labs/database"
-	"github.com/ChomuCake/uni-golang-labs/handlers"
+	"github.com/ChomuCake/uni-golang-labs/logging"
+	"github.com/ChomuCake/uni-golang-labs/migration"
+	"github.com/ChomuCake/uni-golang-labs/scheduler"
+	"github.com/ChomuCake/uni-golang-labs/tracing"
 	_ "github.com/go-sql-driver/mysql"
 )
 
-func init() {
-	err := db.InitDB()
+var (
+	configFile = flag.String("config", "", "path to a YAML or TOML configuration file (default: $CONFIG_FILE)")
+	inMemory   = flag.Bool("in-memory", false, "keep all data in process memory instead of a database (data is lost on exit, same as DB_DRIVER=memory)")
+	migrate    = flag.Bool("migrate", true, "apply pending database migrations on startup")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run запускає сервер і блокується до сигналу SIGINT/SIGTERM, після чого дає незавершеним запитам
+// до server.shutdown_timeout на завершення, зупиняє планувальник і закриває пул з'єднань з базою
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		return err
+	}
+
+	level, err := logging.ParseLevel(cfg.Log.Level)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	slog.SetDefault(logging.New(os.Stderr, level))
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Спани, накопичені до зупинки, надсилаються навіть після сигналу завершення
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("tracing: failed to flush spans", "error", err)
+		}
+	}()
+	if *inMemory {
+		cfg.Database.Driver = db.DriverMemory
+	}
+	if cfg.Auth.JWTSecret == config.DevJWTSecret {
+		slog.Warn("dev mode: using the development JWT secret; set " + config.EnvJWTSecret + " or auth.jwt_secret")
+	}
+
+	storage, err := db.Connect(ctx, cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	application := app.New(cfg, storage)
+	defer application.Close()
+
+	// go run . migrate up|down [steps|all -yes]|version
+	if flag.Arg(0) == "migrate" {
+		return runMigrate(ctx, storage, flag.Args()[1:])
+	}
+
+	if *migrate && storage.Driver != db.DriverMemory {
+		applied, err := migration.Up(ctx, storage.DB, storage.Driver)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			slog.Info("applied migrations", "count", applied)
+		}
+	}
+
+	// Автоматичне очищення кошика від витрат, старших за retention.trash
+	sched := scheduler.New()
+	sched.Add(scheduler.Job{
+		Name:     "purge-trash",
+		Interval: time.Hour,
+		Run: func() error {
+			_, err := application.Storage.Expenses.PurgeTrash(context.Background(), time.Now().Add(-cfg.Retention.Trash))
+			return err
+		},
+	})
+	// Видалення прострочених ключів ідемпотентності
+	sched.Add(scheduler.Job{
+		Name:     "purge-idempotency-keys",
+		Interval: time.Hour,
+		Run: func() error {
+			_, err := application.Storage.Idempotency.PurgeExpiredIdempotencyKeys(context.Background(), time.Now())
+			return err
+		},
+	})
+	sched.Start()
+	defer sched.Stop()
+	application.Scheduler = sched
+
+	server := &http.Server{
+		Addr:    cfg.Server.Addr,
+		Handler: application.Handler(),
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", cfg.Server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop() // Повторний сигнал завершує процес негайно
+
+	slog.Info("shutting down", "drain_timeout", cfg.Server.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
 	}
+	return nil
 }
 
-func main() {
-	defer db.CloseDB()
+// runMigrate виконує команду migrate: up, down [steps|all -yes] (одна міграція, якщо steps не задано) або version
+func runMigrate(ctx context.Context, storage *db.Storage, args []string) error {
+	if storage.Driver == db.DriverMemory {
+		return fmt.Errorf("migrate: the in-memory storage has no schema")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [steps|all -yes]|version")
+	}
+
+	conn := storage.DB
+	switch args[0] {
+	case "up":
+		applied, err := migration.Up(ctx, conn, storage.Driver)
+		if err != nil {
+			return err
+		}
+		slog.Info("applied migrations", "count", applied)
+	case "down":
+		steps, err := parseDownSteps(args[1:])
+		if err != nil {
+			return err
+		}
+		reverted, err := migration.Down(ctx, conn, storage.Driver, steps)
+		if err != nil {
+			return err
+		}
+		slog.Info("reverted migrations", "count", reverted)
+	case "version":
+		version, err := migration.Version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		fmt.Println(version)
+	default:
+		return fmt.Errorf("migrate: unknown command %q", args[0])
+	}
 
-	fs := http.FileServer(http.Dir("./frontend"))
-	http.Handle("/", fs)
+	return nil
+}
 
-	http.HandleFunc("/register", handlers.RegisterHandler)
-	http.HandleFunc("/login", handlers.LoginHandler)
-	http.HandleFunc("/expenses", handlers.ExpensesHandler)
-	http.HandleFunc("/expenses/", handlers.ExpensesHandler)
+// parseDownSteps розбирає аргументи migrate down. Без аргументів відкочується одна міграція;
+// відкат усіх міграцій видаляє всі дані, тому вимагає явного "all" разом із прапорцем -yes.
+func parseDownSteps(args []string) (int, error) {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	confirmed := flags.Bool("yes", false, "confirm reverting all migrations")
 
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	// Прапорець допускається як до, так і після кількості кроків
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+	positional := flags.Args()
+	if len(positional) > 0 {
+		if err := flags.Parse(positional[1:]); err != nil {
+			return 0, err
+		}
+		if flags.NArg() > 0 {
+			return 0, fmt.Errorf("migrate: unexpected argument %q", flags.Arg(0))
+		}
+	}
+
+	if len(positional) == 0 {
+		return 1, nil
+	}
+	if positional[0] == "all" {
+		if !*confirmed {
+			return 0, fmt.Errorf("migrate: reverting all migrations drops all data; run migrate down all -yes to confirm")
+		}
+		return migration.All, nil
+	}
+
+	steps, err := strconv.Atoi(positional[0])
+	if err != nil || steps <= 0 {
+		return 0, fmt.Errorf("migrate: invalid number of steps %q", positional[0])
+	}
+	return steps, nil
 }
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrument_CountsRequestsByRouteAndStatus(t *testing.T) {
+	// Arrange
+	m := New()
+	handler := m.Instrument("/expenses/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/expenses/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	// Act
+	for _, path := range []string{"/expenses/1", "/expenses/2", "/expenses/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Assert: шлях не потрапляє в мітки, лише шаблон маршруту
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("/expenses/", "GET", "200")); got != 2 {
+		t.Errorf("Отримано %v запитів зі статусом 200, очікувалося 2", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("/expenses/", "GET", "404")); got != 1 {
+		t.Errorf("Отримано %v запитів зі статусом 404, очікувалося 1", got)
+	}
+	if got := testutil.CollectAndCount(m.requestDuration); got != 1 {
+		t.Errorf("Отримано %d серій тривалості, очікувалася 1", got)
+	}
+}
+
+// stubUserDB повертає sql.ErrNoRows для невідомого юзера та помилку сервера для ID 0
+type stubUserDB struct {
+	database.UserDB
+}
+
+func (db *stubUserDB) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	switch userID {
+	case 0:
+		return models.User{}, errors.New("server error")
+	case 1:
+		return models.User{ID: 1}, nil
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func TestInstrumentStorage_ObservesQueryResult(t *testing.T) {
+	// Arrange
+	m := New()
+	storage := m.InstrumentStorage(&database.Storage{Users: &stubUserDB{}})
+	ctx := t.Context()
+
+	// Act
+	for _, id := range []int{0, 1, 1, 2} {
+		storage.Users.GetUserByID(ctx, id)
+	}
+
+	// Assert
+	for result, want := range map[string]uint64{"ok": 2, "not_found": 1, "error": 1} {
+		labels := map[string]string{"store": "users", "method": "GetUserByID", "result": result}
+		if got := histogramCount(t, m, labels); got != want {
+			t.Errorf("Отримано %d операцій з результатом %s, очікувалося %d", got, result, want)
+		}
+	}
+}
+
+func TestObserveLogin(t *testing.T) {
+	m := New()
+
+	m.ObserveLogin(true)
+	m.ObserveLogin(false)
+	m.ObserveLogin(false)
+
+	if got := testutil.ToFloat64(m.logins.WithLabelValues(LoginSuccess)); got != 1 {
+		t.Errorf("Отримано %v успішних входів, очікувався 1", got)
+	}
+	if got := testutil.ToFloat64(m.logins.WithLabelValues(LoginFailure)); got != 2 {
+		t.Errorf("Отримано %v невдалих входів, очікувалося 2", got)
+	}
+}
+
+// histogramCount повертає кількість спостережень db_query_duration_seconds з мітками labels
+func histogramCount(t *testing.T, m *Metrics, labels map[string]string) uint64 {
+	t.Helper()
+
+	families, err := m.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "db_query_duration_seconds" {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetValue() != labels[label.GetName()] {
+					continue metrics
+				}
+			}
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
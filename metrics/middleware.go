@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/middleware"
+)
+
+// Instrument рахує запити до next та їхню тривалість з міткою route.
+// route - шаблон маршруту (напр. "/expenses/"), а не фактичний шлях, щоб кількість серій була обмеженою.
+func (m *Metrics) Instrument(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := middleware.NewRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.Status())).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
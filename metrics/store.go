@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+)
+
+// InstrumentStorage повертає копію storage, у якій тривалість кожної операції сховищ записується
+// в метрику db_query_duration_seconds з мітками store, method та result (ok, not_found або error)
+func (m *Metrics) InstrumentStorage(s *database.Storage) *database.Storage {
+	return s.WithObserver(m)
+}
+
+// Observe реалізує database.Observer
+func (m *Metrics) Observe(ctx context.Context, store, method string) (context.Context, func(err error)) {
+	start := time.Now()
+
+	return ctx, func(err error) {
+		m.queryDuration.WithLabelValues(store, method, queryResult(err)).Observe(time.Since(start).Seconds())
+	}
+}
+
+func queryResult(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, database.ErrNotFound):
+		return "not_found"
+	}
+	return "error"
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ChomuCake/uni-golang-labs/logging"
+)
+
+// AccessLog записує в logger кожен запит до next: метод, маршрут route, шлях, статус, тривалість
+// та ID користувача (якщо обробник його встановив через logging.SetUserID)
+func AccessLog(logger *slog.Logger, route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := NewRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.Status()),
+			slog.Float64("duration_ms", milliseconds(time.Since(start))),
+		}
+		if userID, ok := logging.UserID(r.Context()); ok {
+			attrs = append(attrs, slog.Int("user_id", userID))
+		}
+
+		level := slog.LevelInfo
+		if recorder.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// milliseconds переводить тривалість у мілісекунди з точністю до мікросекунди
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
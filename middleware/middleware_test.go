@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "incoming id is kept", incoming: "abc-123", keep: true},
+		{name: "missing id is generated", incoming: ""},
+		{name: "too long id is replaced", incoming: strings.Repeat("a", 65)},
+		{name: "unsafe id is replaced", incoming: "bad id\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var seen string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestIDFromRequest(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, tt.incoming)
+			rr := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(rr, req)
+
+			// Assert
+			got := rr.Header().Get(RequestIDHeader)
+			if got == "" || got != seen {
+				t.Fatalf("Отримано ідентифікатор %q у відповіді та %q в обробнику, очікувалися однакові", got, seen)
+			}
+			if (got == tt.incoming) != tt.keep {
+				t.Errorf("Отримано ідентифікатор %q для вхідного %q", got, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestRequestIDFromRequest_WithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "from-header")
+
+	if got := RequestIDFromRequest(req); got != "from-header" {
+		t.Errorf("Отримано %q, очікувалося %q", got, "from-header")
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+	handler := RequestID(AccessLog(logger, "/expenses/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.SetUserID(r.Context(), 42)
+		w.WriteHeader(http.StatusNotFound)
+	})))
+	req := httptest.NewRequest(http.MethodDelete, "/expenses/7", nil)
+	req.Header.Set(RequestIDHeader, "req-7")
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Запис журналу не є JSON: %q", buf.String())
+	}
+	want := map[string]interface{}{
+		"msg": "request", "method": "DELETE", "route": "/expenses/", "path": "/expenses/7",
+		"status": float64(404), "user_id": float64(42), "request_id": "req-7",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("Поле %s: отримано %v, очікувалося %v", key, record[key], value)
+		}
+	}
+	if _, ok := record["duration_ms"]; !ok {
+		t.Error("Запис не містить duration_ms")
+	}
+}
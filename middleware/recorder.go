@@ -0,0 +1,38 @@
+package middleware
+
+import "net/http"
+
+// Recorder запам'ятовує статус-код відповіді для middleware, що виконуються після обробника
+type Recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// NewRecorder обгортає w; статус за замовчуванням - 200
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status повертає статус-код відповіді
+func (r *Recorder) Status() int {
+	return r.status
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap дає http.ResponseController доступ до початкового ResponseWriter
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,73 @@
+// Package middleware містить HTTP-middleware застосунку: ідентифікатори запитів та журнал доступу.
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/ChomuCake/uni-golang-labs/logging"
+)
+
+// RequestIDHeader - заголовок з ідентифікатором запиту (у запиті та відповіді)
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength - найбільша довжина вхідного ідентифікатора запиту
+const maxRequestIDLength = 64
+
+// RequestID бере ідентифікатор запиту з заголовка X-Request-ID (або генерує новий, якщо його немає
+// чи він некоректний), зберігає його в контексті запиту та повертає в заголовку відповіді
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// RequestIDFromRequest повертає ідентифікатор запиту, встановлений RequestID.
+// Якщо запит обробляється без middleware (напр. у тестах обробників), ідентифікатор береться
+// з заголовка X-Request-ID (обрізаний до 64 символів) або генерується новий.
+func RequestIDFromRequest(r *http.Request) string {
+	if id := logging.RequestID(r.Context()); id != "" {
+		return id
+	}
+
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		if len(id) > maxRequestIDLength {
+			id = id[:maxRequestIDLength]
+		}
+		return id
+	}
+	return newRequestID()
+}
+
+// validRequestID допускає лише короткі ідентифікатори з літер, цифр та символів - _ . :
+// щоб клієнт не міг підставити в журнали довільний текст
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,4 @@
+package migration
+
+// SplitStatements відкриває splitStatements для тестів пакета migration_test
+var SplitStatements = splitStatements
@@ -0,0 +1,413 @@
+// Package migration містить SQL-міграції схеми для кожної підтримуваної СУБД (вбудовані в бінарний файл)
+// та застосовує їх, зберігаючи номери застосованих версій у таблиці schema_versions.
+//
+// Файли міграцій мають вигляд <каталог СУБД>/<версія>_<назва>.up.sql та .down.sql.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql postgres/*.sql
+var files embed.FS
+
+// Назва (MySQL) та ключ (PostgreSQL) рекомендаційного блокування, під яким виконуються міграції
+const (
+	lockName = "uni-golang-labs.migrations"
+	lockKey  = "7262541960339"
+)
+
+// Migration - одна версія схеми
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load повертає міграції для СУБД driver ("mysql", "sqlite" або "postgres"), впорядковані за версією
+func Load(driver string) ([]Migration, error) {
+	names, err := fs.Glob(files, driver+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("migration: no migrations for driver %q", driver)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		version, title, direction, err := parseFileName(path.Base(name))
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration: version %d of %s must have both up and down files", m.Version, driver)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// parseFileName розбирає ім'я на зразок 000002_initial_user_expenses.up.sql
+func parseFileName(name string) (version int, title, direction string, err error) {
+	base := strings.TrimSuffix(name, ".sql")
+	dot := strings.LastIndex(base, ".")
+	underscore := strings.Index(base, "_")
+	if dot < 0 || underscore < 0 || underscore > dot {
+		return 0, "", "", fmt.Errorf("migration: malformed file name %q", name)
+	}
+
+	direction = base[dot+1:]
+	if direction != "up" && direction != "down" {
+		return 0, "", "", fmt.Errorf("migration: malformed file name %q", name)
+	}
+
+	version, err = strconv.Atoi(base[:underscore])
+	if err != nil {
+		return 0, "", "", fmt.Errorf("migration: malformed file name %q", name)
+	}
+
+	return version, base[underscore+1 : dot], direction, nil
+}
+
+// All - кількість кроків для Down, що відкочує всі застосовані міграції
+const All = -1
+
+// Up застосовує всі ще не застосовані міграції та повертає їх кількість.
+// Одночасні Up та Down над однією базою виконуються по черці (див. withLock).
+func Up(ctx context.Context, db *sql.DB, driver string) (count int, err error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return 0, err
+	}
+
+	err = withLock(ctx, db, driver, func() error {
+		count, err = up(ctx, db, driver, migrations)
+		return err
+	})
+	return count, err
+}
+
+func up(ctx context.Context, db *sql.DB, driver string, migrations []Migration) (int, error) {
+	applied, err := appliedVersions(ctx, db, driver, migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		err = apply(ctx, db, m.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, bind(driver, "INSERT INTO schema_versions (version, name, applied_at) VALUES (?, ?, ?)"),
+				m.Version, m.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration: up %06d_%s: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down відкочує steps останніх застосованих міграцій (усі, якщо steps дорівнює All) та повертає їх кількість
+func Down(ctx context.Context, db *sql.DB, driver string, steps int) (count int, err error) {
+	if steps <= 0 && steps != All {
+		return 0, fmt.Errorf("migration: invalid number of steps %d", steps)
+	}
+
+	migrations, err := Load(driver)
+	if err != nil {
+		return 0, err
+	}
+
+	err = withLock(ctx, db, driver, func() error {
+		count, err = down(ctx, db, driver, migrations, steps)
+		return err
+	})
+	return count, err
+}
+
+func down(ctx context.Context, db *sql.DB, driver string, migrations []Migration, steps int) (int, error) {
+	applied, err := appliedVersions(ctx, db, driver, migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && (steps == All || count < steps); i-- {
+		m := migrations[i]
+		if !applied[m.Version] {
+			continue
+		}
+
+		err = apply(ctx, db, m.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, bind(driver, "DELETE FROM schema_versions WHERE version = ?"), m.Version)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration: down %06d_%s: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Version повертає найбільшу застосовану версію схеми (0 - схема порожня)
+func Version(ctx context.Context, db *sql.DB) (int, error) {
+	if err := createVersionTable(ctx, db); err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	err := db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_versions").Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(version.Int64), nil
+}
+
+// Pending повертає кількість ще не застосованих міграцій. На відміну від Up та Version
+// нічого не змінює в базі: якщо таблиці schema_versions немає, повертається помилка.
+func Pending(ctx context.Context, db *sql.DB, driver string) (int, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_versions")
+	if err != nil {
+		return 0, fmt.Errorf("migration: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// apply виконує інструкції міграції та record в одній транзакції.
+// MySQL фіксує DDL неявно, тому там транзакція захищає лише запис про версію.
+func apply(ctx context.Context, db *sql.DB, script string, record func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // після Commit нічого не робить
+
+	for _, statement := range splitStatements(script) {
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if err = record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withLock виконує fn, утримуючи рекомендаційне блокування міграцій (GET_LOCK у MySQL,
+// pg_advisory_lock у PostgreSQL), щоб кілька екземплярів сервера, запущених одночасно,
+// не застосовували ті самі міграції. Блокування належить окремому з'єднанню та знімається
+// разом із ним. SQLite блокування не потребує: транзакції міграцій серіалізує сама база.
+func withLock(ctx context.Context, db *sql.DB, driver string, fn func() error) error {
+	var unlock string
+	switch driver {
+	case "mysql":
+		unlock = "SELECT RELEASE_LOCK('" + lockName + "')"
+	case "postgres":
+		unlock = "SELECT pg_advisory_unlock(" + lockKey + ")"
+	default:
+		return fn()
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if driver == "mysql" {
+		// GET_LOCK повертає 1, якщо блокування отримано, та NULL при помилці; -1 - чекати без обмеження часу
+		var acquired sql.NullInt64
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK('"+lockName+"', -1)").Scan(&acquired)
+		if err == nil && acquired.Int64 != 1 {
+			err = fmt.Errorf("lock %q was not granted", lockName)
+		}
+	} else {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock("+lockKey+")")
+	}
+	if err != nil {
+		return fmt.Errorf("migration: acquire lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), unlock) //nolint:errcheck // з'єднання все одно закривається
+
+	return fn()
+}
+
+func createVersionTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_versions (
+		version INT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	return err
+}
+
+// appliedVersions повертає застосовані версії. Базу, яку раніше мігрували утилітою migrate
+// (таблиця schema_migrations), переводить на schema_versions без повторного застосування міграцій.
+func appliedVersions(ctx context.Context, db *sql.DB, driver string, migrations []Migration) (map[int]bool, error) {
+	if err := createVersionTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	applied, err := readVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) > 0 {
+		return applied, nil
+	}
+
+	var legacyVersion int
+	var dirty bool
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&legacyVersion, &dirty)
+	if err != nil {
+		// Таблиці немає (або вона порожня) - база нова
+		return applied, nil
+	}
+	if dirty {
+		return nil, fmt.Errorf("migration: schema_migrations is dirty at version %d; fix the schema manually", legacyVersion)
+	}
+
+	for _, m := range migrations {
+		if m.Version > legacyVersion {
+			break
+		}
+		_, err = db.ExecContext(ctx, bind(driver, "INSERT INTO schema_versions (version, name, applied_at) VALUES (?, ?, ?)"),
+			m.Version, m.Name, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		applied[m.Version] = true
+	}
+
+	return applied, nil
+}
+
+func readVersions(ctx context.Context, db *sql.DB) (map[int]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_versions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// bind замінює заповнювачі ? на $1, $2... для PostgreSQL
+func bind(driver, query string) string {
+	if driver != "postgres" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitStatements ділить скрипт на окремі інструкції (драйвер MySQL не виконує кілька інструкцій
+// за один запит). Рядки-коментарі "--" відкидаються; крапка з комою всередині рядкових літералів
+// у міграціях не використовується.
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
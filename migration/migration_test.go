@@ -0,0 +1,193 @@
+package migration_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"github.com/ChomuCake/uni-golang-labs/migration"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Open(database.DriverSQLite, database.SQLiteDSN(filepath.Join(t.TempDir(), "migration.db")))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	return count > 0
+}
+
+func TestLoad_AllDrivers(t *testing.T) {
+	for _, driver := range []string{"mysql", "sqlite", "postgres"} {
+		migrations, err := migration.Load(driver)
+		if err != nil {
+			t.Fatalf("Load(%q) returned error: %v", driver, err)
+		}
+
+		if len(migrations) != 5 {
+			t.Errorf("Load(%q): отримано %d міграцій, очікувалося 5", driver, len(migrations))
+		}
+
+		for i, m := range migrations {
+			if i > 0 && m.Version <= migrations[i-1].Version {
+				t.Errorf("Load(%q): міграції не впорядковані за версією", driver)
+			}
+			if m.Up == "" || m.Down == "" || m.Name == "" {
+				t.Errorf("Load(%q): неповна міграція %+v", driver, m)
+			}
+		}
+	}
+
+	if _, err := migration.Load("oracle"); err == nil {
+		t.Error("Очікувалася помилка для невідомої СУБД")
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	db := openSQLite(t)
+
+	// Up застосовує всі міграції
+	applied, err := migration.Up(t.Context(), db, "sqlite")
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if applied != 5 {
+		t.Errorf("Up: застосовано %d міграцій, очікувалося 5", applied)
+	}
+
+	version, err := migration.Version(t.Context(), db)
+	if err != nil {
+		t.Fatalf("Version returned error: %v", err)
+	}
+	if version != 6 {
+		t.Errorf("Отримано версію %d, очікувалося 6", version)
+	}
+
+	// Повторний Up нічого не робить
+	applied, err = migration.Up(t.Context(), db, "sqlite")
+	if err != nil {
+		t.Fatalf("Second Up returned error: %v", err)
+	}
+	if applied != 0 {
+		t.Errorf("Повторний Up: застосовано %d міграцій, очікувалося 0", applied)
+	}
+
+	// Нульова кількість кроків - помилка, а не відкат усіх міграцій
+	if reverted, err := migration.Down(t.Context(), db, "sqlite", 0); err == nil || reverted != 0 {
+		t.Errorf("Down(0): відкочено %d міграцій (%v), очікувалася помилка", reverted, err)
+	}
+
+	// Відкат однієї міграції
+	reverted, err := migration.Down(t.Context(), db, "sqlite", 1)
+	if err != nil {
+		t.Fatalf("Down(1) returned error: %v", err)
+	}
+	if reverted != 1 {
+		t.Errorf("Down(1): відкочено %d міграцій, очікувалося 1", reverted)
+	}
+	if version, _ = migration.Version(t.Context(), db); version != 5 {
+		t.Errorf("Після Down(1) отримано версію %d, очікувалося 5", version)
+	}
+	if tableExists(t, db, "idempotency_keys") {
+		t.Error("Таблиця idempotency_keys мала бути видалена")
+	}
+
+	// Відкат усіх міграцій
+	reverted, err = migration.Down(t.Context(), db, "sqlite", migration.All)
+	if err != nil {
+		t.Fatalf("Down(All) returned error: %v", err)
+	}
+	if reverted != 4 {
+		t.Errorf("Down(All): відкочено %d міграцій, очікувалося 4", reverted)
+	}
+	if version, _ = migration.Version(t.Context(), db); version != 0 {
+		t.Errorf("Після Down(All) отримано версію %d, очікувалося 0", version)
+	}
+	if tableExists(t, db, "expenses") || tableExists(t, db, "users") {
+		t.Error("Таблиці users та expenses мали бути видалені")
+	}
+}
+
+func TestPending(t *testing.T) {
+	db := openSQLite(t)
+	ctx := t.Context()
+
+	// Нова база без таблиці версій - помилка, а не порожній результат
+	if _, err := migration.Pending(ctx, db, "sqlite"); err == nil {
+		t.Error("Pending: очікувалася помилка для бази без schema_versions")
+	}
+
+	if _, err := migration.Up(t.Context(), db, "sqlite"); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if pending, err := migration.Pending(ctx, db, "sqlite"); err != nil || pending != 0 {
+		t.Errorf("Pending після Up: отримано %d (%v), очікувалося 0", pending, err)
+	}
+
+	if _, err := migration.Down(t.Context(), db, "sqlite", 2); err != nil {
+		t.Fatalf("Down returned error: %v", err)
+	}
+	if pending, err := migration.Pending(ctx, db, "sqlite"); err != nil || pending != 2 {
+		t.Errorf("Pending після Down(2): отримано %d (%v), очікувалося 2", pending, err)
+	}
+}
+
+func TestUp_AdoptsLegacySchemaMigrations(t *testing.T) {
+	db := openSQLite(t)
+
+	// База, яку мігрували утилітою migrate до версії 3
+	migrations, err := migration.Load("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range migrations[:2] {
+		if _, err := db.Exec(m.Up); err != nil {
+			t.Fatalf("Failed to apply %d: %v", m.Version, err)
+		}
+	}
+	_, err = db.Exec("CREATE TABLE schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL); INSERT INTO schema_migrations VALUES (3, 0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migration.Up(t.Context(), db, "sqlite")
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if applied != 3 {
+		t.Errorf("Up: застосовано %d міграцій, очікувалося 3", applied)
+	}
+	if version, _ := migration.Version(t.Context(), db); version != 6 {
+		t.Errorf("Отримано версію %d, очікувалося 6", version)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- коментар
+CREATE TABLE a (id INT);
+
+-- ще коментар
+INSERT INTO a VALUES (1);
+`
+	got := migration.SplitStatements(script)
+	want := []string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Отримано %q, очікувалося %q", got, want)
+	}
+}
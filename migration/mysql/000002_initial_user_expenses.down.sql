@@ -1,4 +1,4 @@
--- migration/000002_initial_user_expenses.down
+-- migration/mysql/000002_initial_user_expenses.down
 
 -- Dropping the expenses table
 DROP TABLE expenses;
@@ -0,0 +1,7 @@
+-- migration/mysql/000003_expenses_soft_delete.up
+
+-- Мітка часу переміщення витрати в кошик (NULL - витрата активна)
+ALTER TABLE expenses ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL;
+
+-- Індекс для автоматичного очищення кошика
+CREATE INDEX idx_expenses_deleted_at ON expenses (deleted_at);
@@ -0,0 +1,17 @@
+-- migration/mysql/000004_expense_revisions.up
+
+-- Історія змін витрат (записи лише додаються і ніколи не змінюються)
+CREATE TABLE expense_revisions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    expense_id INT NOT NULL,
+    user_id INT NOT NULL,
+    actor_id INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    before_state JSON NULL,
+    after_state JSON NULL,
+    request_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    INDEX idx_expense_revisions_expense (expense_id, id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (actor_id) REFERENCES users(id)
+);
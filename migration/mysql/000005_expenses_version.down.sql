@@ -0,0 +1,3 @@
+-- migration/mysql/000005_expenses_version.down
+
+ALTER TABLE expenses DROP COLUMN version;
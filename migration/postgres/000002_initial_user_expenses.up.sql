@@ -0,0 +1,19 @@
+-- migration/postgres/000002_initial_user_expenses.up
+
+-- Створення таблиці користувачів
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL
+);
+
+-- Створення таблиці витрат
+CREATE TABLE expenses (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id),
+    date TIMESTAMP NOT NULL,
+    category VARCHAR(255) NOT NULL,
+    amount INT NOT NULL
+);
+
+-- Міграції застосовуються під час запуску сервера або командою: go run . migrate up
@@ -0,0 +1,8 @@
+-- migration/postgres/000003_expenses_soft_delete.down
+
+-- Остаточне видалення витрат, що знаходяться в кошику
+DELETE FROM expenses WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_expenses_deleted_at;
+
+ALTER TABLE expenses DROP COLUMN deleted_at;
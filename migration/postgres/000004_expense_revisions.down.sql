@@ -0,0 +1,3 @@
+-- migration/postgres/000004_expense_revisions.down
+
+DROP TABLE expense_revisions;
@@ -0,0 +1,16 @@
+-- migration/postgres/000004_expense_revisions.up
+
+-- Історія змін витрат (записи лише додаються і ніколи не змінюються)
+CREATE TABLE expense_revisions (
+    id SERIAL PRIMARY KEY,
+    expense_id INT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id),
+    actor_id INT NOT NULL REFERENCES users(id),
+    action VARCHAR(16) NOT NULL,
+    before_state JSONB NULL,
+    after_state JSONB NULL,
+    request_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_expense_revisions_expense ON expense_revisions (expense_id, id);
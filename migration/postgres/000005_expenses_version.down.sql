@@ -0,0 +1,3 @@
+-- migration/postgres/000005_expenses_version.down
+
+ALTER TABLE expenses DROP COLUMN version;
@@ -0,0 +1,16 @@
+-- migration/postgres/000006_idempotency_keys.up
+
+-- Ключі ідемпотентності змінюючих запитів та збережені відповіді на них
+CREATE TABLE idempotency_keys (
+    user_id INT NOT NULL REFERENCES users(id),
+    idem_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    response_headers TEXT NULL,
+    response_body BYTEA NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, idem_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,5 @@
+-- migration/sqlite/000002_initial_user_expenses.down
+
+DROP TABLE expenses;
+
+DROP TABLE users;
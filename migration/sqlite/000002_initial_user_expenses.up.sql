@@ -0,0 +1,20 @@
+-- migration/sqlite/000002_initial_user_expenses.up
+
+-- Створення таблиці користувачів
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL
+);
+
+-- Створення таблиці витрат
+CREATE TABLE expenses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    date TIMESTAMP NOT NULL,
+    category VARCHAR(255) NOT NULL,
+    amount INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Міграції застосовуються під час запуску сервера або командою: go run . migrate up
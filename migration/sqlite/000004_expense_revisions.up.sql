@@ -0,0 +1,18 @@
+-- migration/sqlite/000004_expense_revisions.up
+
+-- Історія змін витрат (записи лише додаються і ніколи не змінюються)
+CREATE TABLE expense_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    expense_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    actor_id INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    before_state TEXT NULL,
+    after_state TEXT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (actor_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_expense_revisions_expense ON expense_revisions (expense_id, id);
@@ -0,0 +1,4 @@
+-- migration/sqlite/000005_expenses_version.up
+
+-- Версія витрати для оптимістичного блокування (збільшується при кожній зміні)
+ALTER TABLE expenses ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
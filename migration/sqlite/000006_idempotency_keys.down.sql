@@ -0,0 +1,3 @@
+-- migration/sqlite/000006_idempotency_keys.down
+
+DROP TABLE idempotency_keys;
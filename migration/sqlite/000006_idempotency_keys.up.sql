@@ -0,0 +1,17 @@
+-- migration/sqlite/000006_idempotency_keys.up
+
+-- Ключі ідемпотентності змінюючих запитів та збережені відповіді на них
+CREATE TABLE idempotency_keys (
+    user_id INTEGER NOT NULL,
+    idem_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_headers TEXT NULL,
+    response_body BLOB NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, idem_key),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,43 @@
+package models
+
+// Типи операцій пакетного запиту
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// Режими виконання пакетного запиту
+const (
+	BatchModeAtomic  = "atomic"  // Усі операції або жодна
+	BatchModePartial = "partial" // Кожна операція застосовується незалежно
+)
+
+// BatchOperation - одна операція пакетного запиту.
+// Для create використовується Expense; для update - ID, Version та Expense; для delete - ID та Version.
+type BatchOperation struct {
+	Op      string  `json:"op"`
+	ID      int     `json:"id,omitempty"`
+	Version int     `json:"version,omitempty"`
+	Expense Expense `json:"expense"`
+}
+
+type BatchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchItemResult - результат однієї операції з HTTP-статусом, що відповідав би окремому запиту
+type BatchItemResult struct {
+	Index   int      `json:"index"`
+	Op      string   `json:"op"`
+	Status  int      `json:"status"`
+	Expense *Expense `json:"expense,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Mode      string            `json:"mode"`
+	Committed bool              `json:"committed"` // Чи було збережено хоча б частину змін
+	Results   []BatchItemResult `json:"results"`
+}
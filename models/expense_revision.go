@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Дії, що фіксуються в історії змін витрати
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+	ActionRevert  = "revert"
+)
+
+// ExpenseRevision - незмінний запис історії: хто, коли і як змінив витрату
+type ExpenseRevision struct {
+	ID        int             `json:"id"`
+	ExpenseID int             `json:"expense_id"`
+	UserID    int             `json:"user_id"`  // Власник витрати
+	ActorID   int             `json:"actor_id"` // Користувач, що виконав зміну
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before"` // Стан витрати до зміни (null для create)
+	After     json.RawMessage `json:"after"`  // Стан витрати після зміни (null для purge)
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
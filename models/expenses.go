@@ -5,10 +5,12 @@ import (
 )
 
 type Expense struct {
-	ID       int       `json:"id"`
-	Date     time.Time `json:"date"`
-	RawDate  string    `json:"rawdate"`
-	Category string    `json:"category"`
-	Amount   int       `json:"amount"`
-	UserID   int       `json:"user_id"`
+	ID        int        `json:"id"`
+	Date      time.Time  `json:"date"`
+	RawDate   string     `json:"rawdate" validate:"date"`
+	Category  string     `json:"category" validate:"required,max=64,text"`
+	Amount    int        `json:"amount" validate:"min=1,max=1000000000"`
+	UserID    int        `json:"user_id"`
+	Version   int        `json:"version"`              // Версія для оптимістичного блокування
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Час переміщення в кошик (nil - витрата активна)
 }
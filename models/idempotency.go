@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyRecord - збережений результат запиту з заголовком Idempotency-Key.
+// Повторний запит з тим самим ключем отримує збережену відповідь замість повторного виконання.
+type IdempotencyRecord struct {
+	UserID      int
+	Key         string
+	Fingerprint string            // Хеш методу, шляху та тіла запиту
+	StatusCode  int               // 0 - перший запит ще виконується
+	Headers     map[string]string // Заголовки відповіді, що відтворюються при повторі
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
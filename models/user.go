@@ -2,6 +2,6 @@ package models
 
 type User struct {
 	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required,min=3,max=32,name"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 }
@@ -0,0 +1,113 @@
+// Package openapi містить специфікацію OpenAPI 3 REST API (openapi.yaml) та обробники,
+// що публікують її у форматі JSON і сторінкою Swagger UI.
+//
+// Специфікація пишеться вручну; тест app перевіряє відповіді справжніх обробників на відповідність їй
+// та те, що кожен маршрут API описаний, тож специфікація не може відстати від коду.
+package openapi
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Шляхи, за якими застосунок публікує специфікацію та Swagger UI
+const (
+	SpecPath = "/openapi.json"
+	UIPath   = "/docs"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// load розбирає та перевіряє специфікацію один раз
+var load = sync.OnceValues(func() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: load: %w", err)
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		return nil, fmt.Errorf("openapi: validate: %w", err)
+	}
+	return doc, nil
+})
+
+var specJSON = sync.OnceValues(func() ([]byte, error) {
+	doc, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+})
+
+// Document повертає розібрану специфікацію. Документ спільний, тож змінювати його не можна.
+func Document() (*openapi3.T, error) {
+	return load()
+}
+
+// Handler віддає специфікацію у форматі JSON
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := specJSON()
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(body)
+	})
+}
+
+// swaggerUIVersion - точна версія swagger-ui-dist, з якої сторінка завантажує Swagger UI. Опубліковану версію
+// пакета npm не можна змінити, тож вихід нових версій 5.x не змінює код сторінки; оновлюється вручну.
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIBase - каталог ресурсів закріпленої версії Swagger UI на CDN
+const swaggerUIBase = "https://unpkg.com/swagger-ui-dist@" + swaggerUIVersion + "/"
+
+// uiScript запускає Swagger UI зі специфікацією SpecPath
+const uiScript = `window.onload = () => {
+            window.ui = SwaggerUIBundle({ url: "` + SpecPath + `", dom_id: "#swagger-ui" });
+        };`
+
+// uiPage - сторінка Swagger UI; скрипти та стилі завантажуються з CDN, специфікація - з SpecPath
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Finance Tracker API</title>
+    <link rel="stylesheet" href="` + swaggerUIBase + `swagger-ui.css" crossorigin="anonymous" referrerpolicy="no-referrer">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="` + swaggerUIBase + `swagger-ui-bundle.js" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
+    <script>` + uiScript + `</script>
+</body>
+</html>
+`
+
+// uiPolicy дозволяє сторінці виконувати лише файли закріпленої версії Swagger UI та власний скрипт uiScript
+// (за його хешем), а запити - лише до цього сервера
+var uiPolicy = func() string {
+	hash := sha256.Sum256([]byte(uiScript))
+	return "default-src 'self'; " +
+		"script-src 'sha256-" + base64.StdEncoding.EncodeToString(hash[:]) + "' " + swaggerUIBase + "swagger-ui-bundle.js; " +
+		"style-src 'unsafe-inline' " + swaggerUIBase + "swagger-ui.css; " +
+		"img-src 'self' data:; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+}()
+
+// UIHandler віддає сторінку Swagger UI для перегляду та виклику API з браузера
+func UIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", uiPolicy)
+		w.Write([]byte(uiPage))
+	})
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job описує періодичне фонове завдання
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Scheduler запускає зареєстровані завдання у фонових горутинах із заданим інтервалом
+type Scheduler struct {
+	jobs    []Job
+	mu      sync.Mutex
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add реєструє завдання; завдання, додані після Start, не запускаються
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Start запускає всі завдання; кожне виконується одразу, а далі - раз на Interval
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job, s.stop)
+	}
+}
+
+// Stop зупиняє завдання та чекає завершення тих, що виконуються
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Running повідомляє, чи запущено планувальник
+func (s *Scheduler) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.running
+}
+
+func (s *Scheduler) loop(job Job, stop <-chan struct{}) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(); err != nil {
+			slog.Error("scheduler: job failed", "job", job.Name, "error", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_RunsJobUntilStopped(t *testing.T) {
+	// Arrange
+	var runs int32
+	s := New()
+	s.Add(Job{
+		Name:     "counter",
+		Interval: 5 * time.Millisecond,
+		Run: func() error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	})
+
+	// Act
+	s.Start()
+	time.Sleep(30 * time.Millisecond)
+	s.Stop()
+	stoppedAt := atomic.LoadInt32(&runs)
+	time.Sleep(20 * time.Millisecond)
+
+	// Assert
+	if stoppedAt < 2 {
+		t.Errorf("Завдання виконалось замало разів: отримано %d, очікувалося щонайменше %d", stoppedAt, 2)
+	}
+
+	if after := atomic.LoadInt32(&runs); after != stoppedAt {
+		t.Errorf("Завдання виконувалось після зупинки: отримано %d, очікувалося %d", after, stoppedAt)
+	}
+
+	if s.Running() {
+		t.Errorf("Планувальник позначено запущеним після Stop")
+	}
+}
+
+func TestScheduler_JobErrorDoesNotStopScheduler(t *testing.T) {
+	// Arrange
+	var runs int32
+	s := New()
+	s.Add(Job{
+		Name:     "failing",
+		Interval: 5 * time.Millisecond,
+		Run: func() error {
+			atomic.AddInt32(&runs, 1)
+			return errors.New("job error")
+		},
+	})
+
+	// Act
+	s.Start()
+	time.Sleep(30 * time.Millisecond)
+	s.Stop()
+
+	// Assert
+	if got := atomic.LoadInt32(&runs); got < 2 {
+		t.Errorf("Завдання не повторювалось після помилки: отримано %d, очікувалося щонайменше %d", got, 2)
+	}
+}
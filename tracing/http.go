@@ -0,0 +1,20 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler створює серверний спан "<метод> <route>" для кожного запиту до next.
+// Якщо запит містить заголовок traceparent, спан стає частиною траси клієнта.
+func Handler(route string, next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, route,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + route
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(attribute.String("http.route", route))),
+	)
+}
@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName - ім'я трасувальника спанів сховищ
+const instrumentationName = "github.com/ChomuCake/uni-golang-labs/database"
+
+// StoreObserver створює спан "<store>.<method>" для кожної операції сховищ (реалізує database.Observer).
+// Driver записується в атрибут db.system.
+type StoreObserver struct {
+	Driver string
+}
+
+// Observe реалізує database.Observer
+func (o StoreObserver) Observe(ctx context.Context, store, method string) (context.Context, func(err error)) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, store+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", o.Driver),
+			attribute.String("db.operation", method),
+			attribute.String("app.store", store),
+		),
+	)
+
+	return ctx, func(err error) {
+		// Відсутній запис і конфлікт версій - очікувані результати, а не збої
+		if err != nil && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, database.ErrNotFound) && !errors.Is(err, database.ErrVersionConflict) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
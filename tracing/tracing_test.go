@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/database"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useRecorder встановлює глобального постачальника трас, що запам'ятовує завершені спани
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return recorder
+}
+
+func TestHandler_ContinuesIncomingTrace(t *testing.T) {
+	// Arrange
+	recorder := useRecorder(t)
+	handler := Handler("/expenses/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/expenses/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Отримано %d спанів, очікувався 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /expenses/" {
+		t.Errorf("Отримано назву спану %q, очікувалася %q", span.Name(), "GET /expenses/")
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Спан не продовжує трасу клієнта: trace_id %s", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("Отримано батьківський спан %s, очікувався 00f067aa0ba902b7", got)
+	}
+}
+
+func TestStoreObserver(t *testing.T) {
+	// Arrange
+	recorder := useRecorder(t)
+	observer := StoreObserver{Driver: "sqlite"}
+
+	// Act
+	_, done := observer.Observe(t.Context(), database.StoreUsers, "GetUserByID")
+	done(database.ErrNotFound)
+	_, done = observer.Observe(t.Context(), database.StoreExpenses, "AddExpense")
+	done(errors.New("disk full"))
+
+	// Assert
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Отримано %d спанів, очікувалося 2", len(spans))
+	}
+	if spans[0].Name() != "users.GetUserByID" || spans[0].Status().Code == codes.Error {
+		t.Errorf("Відсутній запис не є збоєм: %s %v", spans[0].Name(), spans[0].Status())
+	}
+	if spans[1].Name() != "expenses.AddExpense" || spans[1].Status().Code != codes.Error {
+		t.Errorf("Отримано спан %s зі статусом %v, очікувалася помилка", spans[1].Name(), spans[1].Status())
+	}
+}
+
+func TestNewExporter_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := newExporter(t.Context(), config.Tracing{Exporter: ExporterStdout}, &buf)
+	if err != nil {
+		t.Fatalf("newExporter returned error: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("test").Start(context.Background(), "stdout-span")
+	span.End()
+	provider.Shutdown(context.Background())
+
+	if !strings.Contains(buf.String(), `"Name":"stdout-span"`) {
+		t.Errorf("Експортер stdout не записав спан: %q", buf.String())
+	}
+}
+
+func TestSetup_None(t *testing.T) {
+	shutdown, err := Setup(t.Context(), config.Tracing{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	if err := shutdown(t.Context()); err != nil {
+		t.Errorf("shutdown returned error: %v", err)
+	}
+}
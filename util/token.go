@@ -1,27 +1,69 @@
 package util
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/ChomuCake/uni-golang-labs/config"
+	"github.com/ChomuCake/uni-golang-labs/logging"
 	"github.com/ChomuCake/uni-golang-labs/models"
 	"github.com/dgrijalva/jwt-go"
 	_ "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
-type JWTTokenManager struct{}
+// instrumentationName - ім'я трасувальника перевірки токенів
+const instrumentationName = "github.com/ChomuCake/uni-golang-labs/util"
 
-var secretKey = []byte("fd9f5dc52a0b5728c5182c593e0fae7d821e6c7a0fe64b78e67450a0a6860d63")
+// ErrNoSecret - менеджер токенів не має ключа підпису
+var ErrNoSecret = errors.New("jwt: signing secret is not set")
+
+// JWTTokenManager підписує токени ключем Secret; токен дійсний протягом TTL.
+// Нульовий TTL замінюється типовим з config.Default; без Secret токени не видаються й не приймаються (ErrNoSecret).
+type JWTTokenManager struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// NewJWTTokenManager створює менеджер токенів із налаштувань автентифікації
+func NewJWTTokenManager(cfg config.Auth) *JWTTokenManager {
+	return &JWTTokenManager{
+		Secret: []byte(cfg.JWTSecret),
+		TTL:    cfg.TokenTTL,
+	}
+}
+
+func (tm *JWTTokenManager) secret() ([]byte, error) {
+	if len(tm.Secret) == 0 {
+		return nil, ErrNoSecret
+	}
+	return tm.Secret, nil
+}
+
+func (tm *JWTTokenManager) ttl() time.Duration {
+	if tm.TTL <= 0 {
+		return config.Default().Auth.TokenTTL
+	}
+	return tm.TTL
+}
 
 func (tm *JWTTokenManager) GenerateToken(user models.User) (string, error) {
+	secret, err := tm.secret()
+	if err != nil {
+		return "", err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"id":       user.ID,
 		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Токен дійсний протягом 24 годин
+		"exp":      time.Now().Add(tm.ttl()).Unix(),
 	})
 
-	tokenString, err := token.SignedString(secretKey)
+	tokenString, err := token.SignedString(secret)
 	if err != nil {
 		return "", err
 	}
@@ -30,8 +72,13 @@ func (tm *JWTTokenManager) GenerateToken(user models.User) (string, error) {
 }
 
 func (tm *JWTTokenManager) VerifyToken(tokenString string) (interface{}, error) {
+	secret, err := tm.secret()
+	if err != nil {
+		return nil, err
+	}
+
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return secretKey, nil
+		return secret, nil
 	})
 
 	if err != nil {
@@ -71,16 +118,25 @@ func (tm *JWTTokenManager) ExtractToken(r *http.Request) string {
 }
 
 func (tm *JWTTokenManager) ExtractUserIDFromRequest(r *http.Request) (int, error) {
+	_, span := otel.Tracer(instrumentationName).Start(r.Context(), "auth.VerifyToken")
+	defer span.End()
+
 	tokenString := tm.ExtractToken(r)
 
 	token, err := tm.VerifyToken(tokenString)
 	if err != nil {
+		span.SetStatus(codes.Error, "invalid token")
 		return 0, err
 	}
 
 	userID, err := tm.ExtractUserIDFromToken(token)
 	if err != nil {
+		span.SetStatus(codes.Error, "invalid token claims")
 		return 0, err
 	}
+	span.SetAttributes(attribute.Int("enduser.id", userID))
+
+	// Користувач запиту потрапляє в журнал доступу
+	logging.SetUserID(r.Context(), userID)
 	return userID, nil
 }
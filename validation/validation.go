@@ -0,0 +1,203 @@
+// Package validation перевіряє значення полів структур за правилами, оголошеними в тегах validate.
+//
+// Правила перелічуються через кому:
+//
+//	Category string `json:"category" validate:"required,max=64,text"`
+//
+// Підтримуються правила:
+//   - required - значення не порожнє (рядок з самих пробілів вважається порожнім);
+//   - min=N, max=N - для рядків обмежують кількість символів, для чисел - значення;
+//   - date - непорожній рядок є датою у форматі YYYY-MM-DD;
+//   - text - рядок не містить керівних символів;
+//   - name - рядок містить лише літери, цифри, пробіли та символи . _ -, без пробілів на початку й у кінці.
+//
+// Назва поля в помилці береться з тегу json. Неправильний тег - помилка програміста, тому Struct панікує.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DateLayout - формат дати без часу, який приймає правило date
+const DateLayout = "2006-01-02"
+
+// FieldError - помилка значення окремого поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// check перевіряє значення поля й повертає опис помилки або порожній рядок
+type check func(v reflect.Value) string
+
+type fieldRules struct {
+	index  int
+	name   string
+	checks []check
+}
+
+// cache зберігає розібрані правила для кожного типу структури
+var cache sync.Map // reflect.Type -> []fieldRules
+
+// Struct перевіряє всі поля структури v (або вказівника на неї) та повертає помилки в порядку оголошення полів.
+// Порожній результат означає, що значення коректне.
+func Struct(v any) []FieldError {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: %T is not a struct", v))
+	}
+
+	var errs []FieldError
+	for _, field := range rulesFor(value.Type()) {
+		for _, c := range field.checks {
+			if message := c(value.Field(field.index)); message != "" {
+				errs = append(errs, FieldError{Field: field.name, Message: message})
+				break // Для поля достатньо першої помилки
+			}
+		}
+	}
+	return errs
+}
+
+// Prefix додає до назв полів префікс вкладеного об'єкта, напр. operations[0].expense
+func Prefix(prefix string, errs []FieldError) []FieldError {
+	for i := range errs {
+		errs[i].Field = prefix + "." + errs[i].Field
+	}
+	return errs
+}
+
+func rulesFor(t reflect.Type) []fieldRules {
+	if cached, ok := cache.Load(t); ok {
+		return cached.([]fieldRules)
+	}
+
+	var rules []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		rule := fieldRules{index: i, name: name}
+		for _, spec := range strings.Split(tag, ",") {
+			c, err := parseCheck(field.Type.Kind(), spec)
+			if err != nil {
+				panic(fmt.Sprintf("validation: %s.%s: %v", t.Name(), field.Name, err))
+			}
+			rule.checks = append(rule.checks, c)
+		}
+		rules = append(rules, rule)
+	}
+
+	cache.Store(t, rules)
+	return rules
+}
+
+func parseCheck(kind reflect.Kind, spec string) (check, error) {
+	name, arg, hasArg := strings.Cut(spec, "=")
+	isString := kind == reflect.String
+	isInt := kind >= reflect.Int && kind <= reflect.Int64
+
+	switch {
+	case name == "required" && !hasArg:
+		return required, nil
+	case (name == "min" || name == "max") && hasArg && (isString || isInt):
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit in %q", spec)
+		}
+		if isString {
+			return lengthCheck(name == "min", limit), nil
+		}
+		return valueCheck(name == "min", int64(limit)), nil
+	case name == "date" && !hasArg && isString:
+		return date, nil
+	case name == "text" && !hasArg && isString:
+		return text, nil
+	case name == "name" && !hasArg && isString:
+		return displayName, nil
+	}
+	return nil, fmt.Errorf("unsupported rule %q for %s", spec, kind)
+}
+
+func required(v reflect.Value) string {
+	empty := v.IsZero()
+	if v.Kind() == reflect.String {
+		empty = strings.TrimSpace(v.String()) == ""
+	}
+	if empty {
+		return "is required"
+	}
+	return ""
+}
+
+func lengthCheck(isMin bool, limit int) check {
+	return func(v reflect.Value) string {
+		length := utf8.RuneCountInString(v.String())
+		if isMin && length < limit {
+			return fmt.Sprintf("must be at least %d characters long", limit)
+		}
+		if !isMin && length > limit {
+			return fmt.Sprintf("must be at most %d characters long", limit)
+		}
+		return ""
+	}
+}
+
+func valueCheck(isMin bool, limit int64) check {
+	return func(v reflect.Value) string {
+		if isMin && v.Int() < limit {
+			return fmt.Sprintf("must be at least %d", limit)
+		}
+		if !isMin && v.Int() > limit {
+			return fmt.Sprintf("must be at most %d", limit)
+		}
+		return ""
+	}
+}
+
+func date(v reflect.Value) string {
+	if v.String() == "" {
+		return ""
+	}
+	if _, err := time.Parse(DateLayout, v.String()); err != nil {
+		return "must be a date in YYYY-MM-DD format"
+	}
+	return ""
+}
+
+func text(v reflect.Value) string {
+	for _, r := range v.String() {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return "must not contain control characters"
+		}
+	}
+	return ""
+}
+
+func displayName(v reflect.Value) string {
+	s := v.String()
+	if strings.TrimSpace(s) != s {
+		return "must not start or end with a space"
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" ._-", r) {
+			return "may contain only letters, digits, spaces and . _ -"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ChomuCake/uni-golang-labs/models"
+)
+
+func TestStruct_Expense(t *testing.T) {
+	tests := []struct {
+		name     string
+		expense  models.Expense
+		expected []FieldError
+	}{
+		{"valid", models.Expense{Category: "food", Amount: 10, RawDate: "2023-05-27"}, nil},
+		{"valid without date", models.Expense{Category: "Кава & чай", Amount: 1}, nil},
+		{"empty", models.Expense{}, []FieldError{
+			{Field: "category", Message: "is required"},
+			{Field: "amount", Message: "must be at least 1"},
+		}},
+		{"blank category", models.Expense{Category: "   ", Amount: 10}, []FieldError{
+			{Field: "category", Message: "is required"},
+		}},
+		{"negative amount", models.Expense{Category: "food", Amount: -5}, []FieldError{
+			{Field: "amount", Message: "must be at least 1"},
+		}},
+		{"too large", models.Expense{Category: strings.Repeat("к", 65), Amount: 1000000001}, []FieldError{
+			{Field: "category", Message: "must be at most 64 characters long"},
+			{Field: "amount", Message: "must be at most 1000000000"},
+		}},
+		{"control characters and date", models.Expense{Category: "food\n", Amount: 1, RawDate: "27.05.2023"}, []FieldError{
+			{Field: "rawdate", Message: "must be a date in YYYY-MM-DD format"},
+			{Field: "category", Message: "must not contain control characters"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			errs := Struct(tt.expense)
+
+			// Assert
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Errorf("Отримано помилки %v, очікувалося %v", errs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStruct_User(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     models.User
+		expected []FieldError
+	}{
+		{"valid", models.User{Username: "John Doe", Password: "12345678"}, nil},
+		{"empty", models.User{}, []FieldError{
+			{Field: "username", Message: "is required"},
+			{Field: "password", Message: "is required"},
+		}},
+		{"short", models.User{Username: "jo", Password: "1234"}, []FieldError{
+			{Field: "username", Message: "must be at least 3 characters long"},
+			{Field: "password", Message: "must be at least 8 characters long"},
+		}},
+		{"invalid characters", models.User{Username: "john<script>", Password: "12345678"}, []FieldError{
+			{Field: "username", Message: "may contain only letters, digits, spaces and . _ -"},
+		}},
+		{"surrounding spaces", models.User{Username: " john", Password: "12345678"}, []FieldError{
+			{Field: "username", Message: "must not start or end with a space"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			errs := Struct(&tt.user)
+
+			// Assert
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Errorf("Отримано помилки %v, очікувалося %v", errs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStruct_InvalidRule(t *testing.T) {
+	// Arrange
+	type invalid struct {
+		Amount int `json:"amount" validate:"date"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Неправильне правило не спричинило паніку")
+		}
+	}()
+
+	// Act
+	Struct(invalid{})
+}
+
+func TestPrefix(t *testing.T) {
+	// Act
+	errs := Prefix("operations[1].expense", []FieldError{{Field: "amount", Message: "must be at least 1"}})
+
+	// Assert
+	if errs[0].Field != "operations[1].expense.amount" {
+		t.Errorf("Отримано поле %v, очікувалося operations[1].expense.amount", errs[0].Field)
+	}
+}